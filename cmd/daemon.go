@@ -0,0 +1,299 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/volleyhq/volley-cli/internal/config"
+)
+
+// Files of the background listener, in the daemon directory of the config directory
+const (
+	daemonPIDFile    = "listen.pid"
+	daemonLogFile    = "listen.log"
+	daemonSocketFile = "listen.sock"
+)
+
+// How long 'volley daemon stop' waits for queued deliveries to finish
+const daemonStopTimeout = 60 * time.Second
+
+var errDaemonNotRunning = errors.New("no listener is running in the background")
+
+var (
+	logLines  int
+	logFollow bool
+)
+
+var daemonCmd = &cobra.Command{
+	Use:   "daemon",
+	Short: "Manage the listener running in the background",
+	Long: `Manage the listener started with 'volley listen --daemon'.
+
+The background listener keeps forwarding webhooks after the terminal that started it is
+closed. It writes its PID file, log file and control socket to the daemon directory in
+the config directory; these commands talk to it through the control socket.`,
+}
+
+var daemonStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show whether the background listener is running and what it forwards",
+	Args:  cobra.NoArgs,
+	RunE:  runDaemonStatus,
+}
+
+var daemonStopCmd = &cobra.Command{
+	Use:   "stop",
+	Short: "Stop the background listener once queued deliveries are finished",
+	Args:  cobra.NoArgs,
+	RunE:  runDaemonStop,
+}
+
+var daemonLogsCmd = &cobra.Command{
+	Use:   "logs",
+	Short: "Print the background listener's log",
+	Long: `Print the last lines of the background listener's log.
+
+Examples:
+  volley daemon logs
+  volley daemon logs -n 200
+  volley daemon logs --follow`,
+	Args: cobra.NoArgs,
+	RunE: runDaemonLogs,
+}
+
+var daemonReloadCmd = &cobra.Command{
+	Use:   "reload",
+	Short: "Reload the --config file of the background listener",
+	Args:  cobra.NoArgs,
+	RunE:  runDaemonReload,
+}
+
+func init() {
+	daemonLogsCmd.Flags().IntVarP(&logLines, "lines", "n", 50, "Number of lines to print")
+	daemonLogsCmd.Flags().BoolVarP(&logFollow, "follow", "f", false, "Keep printing new lines as they are written")
+
+	daemonCmd.AddCommand(daemonStatusCmd)
+	daemonCmd.AddCommand(daemonStopCmd)
+	daemonCmd.AddCommand(daemonLogsCmd)
+	daemonCmd.AddCommand(daemonReloadCmd)
+	rootCmd.AddCommand(daemonCmd)
+}
+
+func daemonDir() string {
+	return filepath.Join(config.Dir(), "daemon")
+}
+
+func daemonFile(name string) string {
+	return filepath.Join(daemonDir(), name)
+}
+
+func runDaemonStatus(cmd *cobra.Command, args []string) error {
+	status, err := newDaemonClient().status()
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Running:     PID %d since %s (up %s)\n", status.PID, status.StartedAt.Local().Format(time.DateTime), time.Since(status.StartedAt).Round(time.Second))
+	if status.Config != "" {
+		fmt.Printf("Config:      %s\n", status.Config)
+	}
+	fmt.Printf("Log file:    %s\n", status.LogFile)
+	if status.Paused {
+		fmt.Println("Forwarding:  paused")
+	}
+	fmt.Printf("In flight:   %d\n", status.InFlight)
+	if status.LastEvent != nil {
+		fmt.Printf("Last event:  %s from %s at %s\n", status.LastEvent.EventID, status.LastEvent.SourceSlug, status.LastEvent.ReceivedAt.Local().Format(time.DateTime))
+	}
+
+	fmt.Println("\nSources:")
+	for _, src := range status.Sources {
+		fmt.Printf("  %s (%s)", src.Slug, src.IngestionID)
+		if src.Connection != "" {
+			fmt.Printf(" via connection %s", src.Connection)
+		}
+		if len(src.Targets) > 0 {
+			fmt.Printf(" -> %s", strings.Join(src.Targets, ", "))
+		}
+		fmt.Println()
+	}
+	return nil
+}
+
+func runDaemonStop(cmd *cobra.Command, args []string) error {
+	client := newDaemonClient()
+	status, err := client.status()
+	if err != nil {
+		return err
+	}
+	if err := client.do("POST", "/stop", nil); err != nil {
+		return err
+	}
+
+	fmt.Printf("Stopping background listener (PID %d)...\n", status.PID)
+	deadline := time.Now().Add(daemonStopTimeout)
+	for time.Now().Before(deadline) {
+		time.Sleep(200 * time.Millisecond)
+		if _, err := client.status(); errors.Is(err, errDaemonNotRunning) {
+			fmt.Println("✓ Stopped")
+			return nil
+		}
+	}
+	return fmt.Errorf("the background listener is still running after %s; see 'volley daemon logs'", daemonStopTimeout)
+}
+
+func runDaemonReload(cmd *cobra.Command, args []string) error {
+	if err := newDaemonClient().do("POST", "/reload", nil); err != nil {
+		return err
+	}
+	fmt.Println("✓ Reloaded")
+	return nil
+}
+
+func runDaemonLogs(cmd *cobra.Command, args []string) error {
+	path := daemonFile(daemonLogFile)
+	lines, err := tailLines(path, logLines)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("no daemon log yet; start a background listener with 'volley listen --daemon'")
+		}
+		return err
+	}
+	for _, line := range lines {
+		fmt.Println(line)
+	}
+	if !logFollow {
+		return nil
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	return followFile(ctx, path)
+}
+
+// tailLines returns the last n lines of a file
+func tailLines(path string, n int) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var lines []string
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+		if len(lines) > n {
+			lines = lines[1:]
+		}
+	}
+	return lines, scanner.Err()
+}
+
+// followFile prints what is appended to a file until ctx is cancelled, starting over
+// when the file is truncated
+func followFile(ctx context.Context, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	offset, err := f.Seek(0, io.SeekEnd)
+	if err != nil {
+		return err
+	}
+	ticker := time.NewTicker(500 * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+
+		info, err := f.Stat()
+		if err != nil {
+			return err
+		}
+		if info.Size() < offset {
+			offset, _ = f.Seek(0, io.SeekStart)
+		}
+		n, err := io.Copy(os.Stdout, f)
+		if err != nil {
+			return err
+		}
+		offset += n
+	}
+}
+
+// daemonClient talks to the background listener over its control socket
+type daemonClient struct {
+	http *http.Client
+}
+
+func newDaemonClient() *daemonClient {
+	socket := daemonFile(daemonSocketFile)
+	dialer := &net.Dialer{Timeout: 2 * time.Second}
+	transport := &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return dialer.DialContext(ctx, "unix", socket)
+		},
+	}
+	return &daemonClient{http: &http.Client{Transport: transport, Timeout: 10 * time.Second}}
+}
+
+func (c *daemonClient) status() (*daemonStatus, error) {
+	var status daemonStatus
+	if err := c.do("GET", "/status", &status); err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
+
+// do sends a request to the control socket and decodes the JSON response into result
+func (c *daemonClient) do(method, path string, result interface{}) error {
+	req, err := http.NewRequest(method, "http://daemon"+path, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		// No socket or nobody listening on it: a PID file left behind is stale
+		var opErr *net.OpError
+		if errors.As(err, &opErr) && opErr.Op == "dial" {
+			os.Remove(daemonFile(daemonPIDFile))
+			return errDaemonNotRunning
+		}
+		return fmt.Errorf("failed to reach the background listener: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		var body struct {
+			Error string `json:"error"`
+		}
+		json.NewDecoder(resp.Body).Decode(&body)
+		if body.Error == "" {
+			body.Error = resp.Status
+		}
+		return errors.New(body.Error)
+	}
+	if result != nil {
+		return json.NewDecoder(resp.Body).Decode(result)
+	}
+	return nil
+}
@@ -0,0 +1,14 @@
+//go:build windows
+
+package cmd
+
+import "syscall"
+
+// DETACHED_PROCESS: the background listener gets no console of its own
+const detachedProcess = 0x00000008
+
+// detachedProcAttr starts the background listener without a console so it keeps
+// running when the terminal that started it is closed
+func detachedProcAttr() *syscall.SysProcAttr {
+	return &syscall.SysProcAttr{CreationFlags: detachedProcess | syscall.CREATE_NEW_PROCESS_GROUP}
+}
@@ -0,0 +1,286 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/volleyhq/volley-cli/internal/dlq"
+	"github.com/volleyhq/volley-cli/internal/signature"
+)
+
+var (
+	redeliverAll bool
+	redeliverTo  string
+)
+
+var dlqCmd = &cobra.Command{
+	Use:   "dlq",
+	Short: "Inspect and redeliver events that failed to forward",
+	Long: `Manage the local dead-letter queue.
+
+When 'volley listen' cannot deliver an event to a local target (the endpoint returned
+an error status or was unreachable), the full event is saved to the dead-letter queue
+in the config directory together with the error. Use these commands to inspect the
+failures and push the events through the same forwarding path again.`,
+}
+
+var dlqListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List events in the dead-letter queue",
+	Args:  cobra.NoArgs,
+	RunE:  runDLQList,
+}
+
+var dlqShowCmd = &cobra.Command{
+	Use:   "show <id>",
+	Short: "Show a dead-lettered event with its headers, body and error",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runDLQShow,
+}
+
+var dlqRedeliverCmd = &cobra.Command{
+	Use:   "redeliver [id...]",
+	Short: "Forward dead-lettered events again",
+	Long: `Forward dead-lettered events to their original target again, with the header
+rules (--add-header, --remove-header, --rename-header) they were forwarded with.
+Events whose --transform command failed are run through it again first.
+Events that were forwarded with --resign are signed again with the same provider, using
+--resign-secret or the VOLLEY_RESIGN_SECRET environment variable; the secret is never
+stored in the queue.
+Events that are delivered successfully are removed from the queue; events that fail
+again stay in the queue with the new error.
+
+Examples:
+  volley dlq redeliver evt_123-1a2b3c4d
+  volley dlq redeliver --all
+  volley dlq redeliver --all --forward-to http://localhost:4000/webhook
+  volley dlq redeliver --all --ca-cert ./dev-ca.pem
+  volley dlq redeliver --all --resign-secret whsec_local...`,
+	RunE: runDLQRedeliver,
+}
+
+func init() {
+	dlqRedeliverCmd.Flags().BoolVar(&redeliverAll, "all", false, "Redeliver every event in the queue")
+	dlqRedeliverCmd.Flags().StringVarP(&redeliverTo, "forward-to", "f", "", "Deliver to this URL instead of the original target")
+	dlqRedeliverCmd.Flags().BoolVar(&insecureTLS, "insecure", false, "Skip TLS certificate verification for HTTPS targets")
+	dlqRedeliverCmd.Flags().StringVar(&caCert, "ca-cert", "", "PEM file with CA certificates to trust for HTTPS targets")
+	dlqRedeliverCmd.Flags().StringVar(&clientCert, "client-cert", "", "PEM client certificate for HTTPS targets that require mTLS")
+	dlqRedeliverCmd.Flags().StringVar(&clientKey, "client-key", "", "PEM private key of --client-cert")
+	dlqRedeliverCmd.Flags().StringVar(&resignSecret, "resign-secret", "", "Signing secret for events forwarded with --resign (default: $VOLLEY_RESIGN_SECRET)")
+
+	dlqCmd.AddCommand(dlqListCmd)
+	dlqCmd.AddCommand(dlqShowCmd)
+	dlqCmd.AddCommand(dlqRedeliverCmd)
+	rootCmd.AddCommand(dlqCmd)
+}
+
+func runDLQList(cmd *cobra.Command, args []string) error {
+	store, err := dlq.Open(dlq.DefaultDir())
+	if err != nil {
+		return err
+	}
+	entries, err := store.List()
+	if err != nil {
+		return err
+	}
+
+	if len(entries) == 0 {
+		fmt.Println("Dead-letter queue is empty")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tSOURCE\tTARGET\tERROR\tATTEMPTS\tFAILED AT")
+	for _, e := range entries {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%d\t%s\n", e.ID, e.Event.SourceSlug, e.TargetURL, e.Error, e.Attempts, e.FailedAt.Local().Format(time.DateTime))
+	}
+	return w.Flush()
+}
+
+func runDLQShow(cmd *cobra.Command, args []string) error {
+	store, err := dlq.Open(dlq.DefaultDir())
+	if err != nil {
+		return err
+	}
+	entry, err := store.Get(args[0])
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("ID:          %s\n", entry.ID)
+	fmt.Printf("Event ID:    %s\n", entry.Event.EventID)
+	fmt.Printf("Source:      %s (ID: %d)\n", entry.Event.SourceSlug, entry.Event.SourceID)
+	fmt.Printf("Received at: %s\n", entry.Event.CreatedAt.Local().Format(time.RFC3339))
+	if entry.Event.Method != "" {
+		path := entry.Event.Path
+		if path == "" {
+			path = "/"
+		}
+		request := entry.Event.Method + " " + path
+		if entry.Event.QueryString != "" {
+			request += "?" + entry.Event.QueryString
+		}
+		fmt.Printf("Request:     %s\n", request)
+	}
+	fmt.Printf("Target:      %s\n", entry.TargetURL)
+	if entry.ResignProvider != "" {
+		fmt.Printf("Re-signed:   %s\n", entry.ResignProvider)
+	}
+	if entry.StatusCode != 0 {
+		fmt.Printf("Status:      %d\n", entry.StatusCode)
+	}
+	fmt.Printf("Error:       %s\n", entry.Error)
+	fmt.Printf("Attempts:    %d\n", entry.Attempts)
+	fmt.Printf("Failed at:   %s\n", entry.FailedAt.Local().Format(time.RFC3339))
+
+	fmt.Println("\nHeaders:")
+	names := make([]string, 0, len(entry.Event.Headers))
+	for name := range entry.Event.Headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		for _, value := range entry.Event.Headers[name] {
+			fmt.Printf("  %s: %s\n", name, value)
+		}
+	}
+
+	fmt.Println("\nBody:")
+	fmt.Println(prettyBody(entry.Event.RawBody))
+	return nil
+}
+
+func runDLQRedeliver(cmd *cobra.Command, args []string) error {
+	if redeliverAll == (len(args) > 0) {
+		return fmt.Errorf("specify entry IDs or --all")
+	}
+
+	tlsConfig, err := tlsOptions{insecure: insecureTLS, caCert: caCert, clientCert: clientCert, clientKey: clientKey}.config()
+	if err != nil {
+		return err
+	}
+	clients := newForwardClients(tlsConfig)
+
+	store, err := dlq.Open(dlq.DefaultDir())
+	if err != nil {
+		return err
+	}
+
+	var entries []dlq.Entry
+	if redeliverAll {
+		entries, err = store.List()
+		if err != nil {
+			return err
+		}
+	} else {
+		for _, id := range args {
+			entry, err := store.Get(id)
+			if err != nil {
+				return err
+			}
+			entries = append(entries, *entry)
+		}
+	}
+
+	if len(entries) == 0 {
+		fmt.Println("Dead-letter queue is empty")
+		return nil
+	}
+
+	// Entries forwarded with --resign are signed again, which needs the secret
+	secret := resignSecret
+	if secret == "" {
+		secret = os.Getenv("VOLLEY_RESIGN_SECRET")
+	}
+	if secret == "" {
+		for _, entry := range entries {
+			if entry.ResignProvider != "" {
+				return fmt.Errorf("entry %s was forwarded with --resign %s: pass --resign-secret or set VOLLEY_RESIGN_SECRET to redeliver it", entry.ID, entry.ResignProvider)
+			}
+		}
+	}
+
+	// Entries that failed on an --exec-persistent target share its process
+	defer stopPersistentProcesses()
+
+	failed := 0
+	for i := range entries {
+		entry := &entries[i]
+		target := entry.TargetURL
+		if redeliverTo != "" {
+			target = redeliverTo
+		}
+
+		// Events whose transform failed go through it again first
+		event := &entry.Event
+		var err error
+		if entry.Transform != "" {
+			event, err = transformEvent(context.Background(), entry.Transform, &entry.Event)
+			if err == nil && event == nil {
+				fmt.Printf("- Skipped %s (dropped by transform)\n", entry.ID)
+				if err := store.Remove(entry.ID); err != nil {
+					fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+				}
+				continue
+			}
+		}
+
+		// Rewrite the headers the same way the listener did
+		var headers headerRules
+		if err == nil {
+			headers, err = parseHeaderRules(entry.AddHeaders, entry.RemoveHeaders, entry.RenameHeaders)
+		}
+		var signer *signature.Signer
+		if err == nil && entry.ResignProvider != "" {
+			signer, err = signature.NewSigner(entry.ResignProvider, secret, entry.ResignHeader)
+		}
+		if err == nil {
+			opts := forwardOptions{preserveRequest: entry.PreserveRequest, signer: signer, headers: headers, clients: clients}
+			_, err = forwardEvent(context.Background(), event, target, opts)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "✗ Failed to redeliver %s -> %s: %v\n", entry.ID, target, err)
+			failed++
+
+			entry.Error = err.Error()
+			entry.StatusCode = 0
+			var fwdErr *forwardError
+			if errors.As(err, &fwdErr) {
+				entry.StatusCode = fwdErr.StatusCode
+			}
+			entry.Attempts++
+			entry.FailedAt = time.Now()
+			if err := store.Update(entry); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+			}
+			continue
+		}
+
+		fmt.Printf("✓ Redelivered %s -> %s\n", entry.ID, target)
+		if err := store.Remove(entry.ID); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d event(s) could not be redelivered", failed, len(entries))
+	}
+	return nil
+}
+
+// prettyBody indents JSON bodies for display and returns anything else unchanged
+func prettyBody(body string) string {
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, []byte(body), "", "  "); err != nil {
+		return body
+	}
+	return buf.String()
+}
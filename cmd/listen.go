@@ -2,11 +2,19 @@ package cmd
 
 import (
 	"bytes"
+	"context"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
+	"net/url"
 	"os"
 	"os/signal"
+	"path/filepath"
+	"sort"
 	"strconv"
+	"strings"
+	"sync"
 	"syscall"
 	"time"
 
@@ -14,13 +22,135 @@ import (
 	"github.com/spf13/viper"
 	"github.com/volleyhq/volley-cli/internal/api"
 	"github.com/volleyhq/volley-cli/internal/config"
+	"github.com/volleyhq/volley-cli/internal/dedupe"
+	"github.com/volleyhq/volley-cli/internal/dlq"
+	"github.com/volleyhq/volley-cli/internal/filter"
+	"github.com/volleyhq/volley-cli/internal/signature"
+	"golang.org/x/term"
 )
 
 var (
-	forwardURL string
-	sourceID   string
+	forwardURLs []string
+	sourceIDs   []string
+	projectRef  string
+	allSources  bool
+	routeSpecs  []string
+
+	listenConfigFile string
+	daemonize        bool
+
+	resume     bool
+	since      string
+	last       int
+	transport  string
+	listenMode string
+	connRefs   []string
+
+	retries      int
+	retryBackoff time.Duration
+	retryOn      []string
+	useDLQ       bool
+
+	concurrency int
+	ordered     bool
+
+	dedupeSize   int
+	dedupeWindow time.Duration
+	dedupeFile   string
+
+	preserveRequest bool
+
+	printResponse bool
+
+	useUI       bool
+	inspectAddr string
+	metricsAddr string
+
+	verifyProvider  string
+	verifySecret    string
+	signatureHeader string
+	dropInvalid     bool
+
+	resignProvider string
+	resignSecret   string
+
+	bodyFilters   []string
+	headerFilters []string
+
+	transformCmd string
+
+	execCommands   []string
+	execPersistent bool
+
+	outputDir   string
+	stdoutJSONL bool
+
+	insecureTLS bool
+	caCert      string
+	clientCert  string
+	clientKey   string
+
+	addHeaders    []string
+	removeHeaders []string
+	renameHeaders []string
 )
 
+// backfillPageSize is the number of events requested per page while catching up
+const backfillPageSize = 100
+
+// minDedupeSize keeps the dedupe store larger than any single poll result,
+// so IDs returned by one poll are never evicted before the next one
+const minDedupeSize = 100
+
+// listener holds the state shared by the backfill and poll loops of one listen session
+type listener struct {
+	apiClient *api.Client
+	sources   map[uint64]*listenSource // by source ID
+	retry     retryPolicy
+	forward   forwardOptions
+	dlq       *dlq.Store // nil when the dead-letter queue is disabled
+
+	// Recent events with the local responses to each delivery
+	history       *history
+	printResponse bool
+
+	// Events must meet every filter to be forwarded
+	filters []filter.Filter
+
+	// Record every received event, with or without targets
+	sinks []eventSink
+
+	// Command every event is piped through before forwarding; empty to forward as is
+	transform string
+
+	// Checks signatures before forwarding; nil unless --verify is given
+	verifier    *signature.Verifier
+	dropInvalid bool
+
+	// Set when started with --config
+	config *configReloader
+
+	// Counters and poll health, served with --metrics-addr
+	metrics *listenMetrics
+
+	// Guards the settings a --config reload replaces: filters, retry, forward.headers
+	// and the sources' targets
+	rulesMu sync.RWMutex
+
+	// Delivers events off the poll loop on a pool of workers
+	dispatcher *dispatcher
+
+	// Track forwarded event IDs to avoid duplicates. Bounded by size and time window,
+	// optionally persisted so restarts do not deliver the same event twice.
+	seen         *dedupe.Store
+	dedupeWindow time.Duration
+
+	// In --resume mode the last forwarded event of every source is persisted to the
+	// config directory. mu guards the sources' checkpoints since workers update them concurrently.
+	resume bool
+	mu     sync.Mutex
+}
+
 var listenCmd = &cobra.Command{
 	Use:   "listen",
 	Short: "Forward webhooks to a local endpoint",
@@ -28,17 +158,200 @@ var listenCmd = &cobra.Command{
 This is useful for local development and testing.
 
 The CLI will poll for new webhook events and forward them to your local server.
+--forward-to can be repeated to fan out every event to several local endpoints
+from a single listener; each event is fetched once and delivered to every target.
+
+With --resume the CLI remembers the last event it forwarded for each source and,
+on the next start, first replays everything that arrived while it was stopped.
+--since and --last replay recent history instead: historical events are forwarded
+in chronological order before live ones.
+
+Sources with connections are listened to in connection mode, which follows the
+delivery attempts of one connection; sources without are listened to in direct event
+mode. --mode direct or --mode connection makes the choice explicit. When a source has
+several connections, --connection picks one by ID or name; without it the CLI asks
+which one to use when stdin is a terminal, and otherwise uses the first and says so.
+
+In direct event mode the CLI prefers a server-push stream (--transport auto) and
+falls back to polling when the server does not offer one or while it reconnects.
+
+Failed forwards can be retried locally with exponential backoff (--retries), which
+helps when the local server is in the middle of a restart. Events that still fail
+are saved to a local dead-letter queue; see 'volley dlq --help'.
+
+Events are forwarded by a pool of --concurrency workers. With --ordered, events from
+the same source are always delivered one at a time in the order they were created.
+On Ctrl+C the CLI stops polling and waits for in-flight deliveries to finish; press
+Ctrl+C again to abort them.
+
+Event IDs that were already handled are remembered in a bounded store (--dedupe-size,
+--dedupe-window). With --resume or --dedupe-file the store is kept on disk so a
+restart never delivers the same event twice; an ID is only written once its event was
+handled, so events still queued when the listener is killed are fetched again.
+
+Events are POSTed to the --forward-to URL as is. With --preserve-request the original
+HTTP method is kept and the sub-path and query string the provider called
+(/hook/<id>/sub/path?x=1) are appended to the target URL.
+
+Every delivery is reported with the local endpoint's status code and latency.
+Add --print-response to also print the response headers and body.
+
+With --ui the CLI shows an interactive dashboard instead of a stream of lines: a live
+table of events where the selected event can be inspected (Enter) and re-forwarded (r),
+forwarding can be paused and resumed (p) and the list can be filtered (/).
+
+--inspect starts a local web inspector on the given address that lists every event
+seen by the listener with its headers, body and local responses, and can replay them.
+An address without a host (:4040) listens on 127.0.0.1 only, and requests from other
+hosts or web pages are refused. Bind it to the address of one interface to reach it
+from other machines; wildcard addresses (0.0.0.0) are refused.
+
+--verify checks each event's signature with the provider's scheme before forwarding,
+using --secret or the VOLLEY_WEBHOOK_SECRET environment variable. Events with an
+invalid signature are flagged and still forwarded, or skipped with --drop-invalid.
+
+--resign replaces the provider's signature headers with ones computed with a local
+development secret (--resign-secret or VOLLEY_RESIGN_SECRET) right before each request,
+with a fresh timestamp where the scheme signs one.
+
+--verify and --resign use a single secret, so they are only available when listening to
+one source.
+
+--filter and --filter-header limit which events are forwarded: an event must meet every
+filter, the others are reported as skipped. --filter matches a field of the JSON body
+(type = invoice.paid, type in [invoice.paid, invoice.failed], data.object.livemode != true,
+type not in [...]); --filter-header matches a header (X-GitHub-Event=push, X-GitHub-Event!=ping).
+
+Original headers are forwarded except hop-by-hop headers (Connection and the headers it
+lists, Keep-Alive, Proxy-*, TE, Trailer, Transfer-Encoding, Upgrade), Host and
+Content-Length. --rename-header Old=New is applied to the original headers, then the
+CLI adds its X-Volley-* headers, then --remove-header and --add-header 'Name: value'
+are applied, so they can also drop or override the CLI's own headers.
+
+--transform runs a command for every event before it is forwarded. The command gets the
+event as JSON on stdin (event_id, headers, raw_body, method, path, ...) and prints the
+event to forward on stdout; printing nothing drops the event. Changing the body or
+headers breaks provider signatures unless --resign is used as well.
+
+--exec delivers events to a local command instead of (or next to) an HTTP endpoint.
+The command is started for every event with the raw body on stdin, headers in HTTP_*
+environment variables (HTTP_X_GITHUB_EVENT) and VOLLEY_EVENT_ID, VOLLEY_SOURCE_SLUG,
+VOLLEY_METHOD, VOLLEY_PATH and VOLLEY_QUERY_STRING; a non-zero exit status fails the
+delivery without retries. With --exec-persistent one process is kept running instead: it receives
+each event as a JSON line on stdin and must answer with {"ok": true} or
+{"ok": false, "error": "..."} on a line of its own.
+
+--output-dir writes every received event to a directory (the raw body plus a
+.headers.json sidecar) and --stdout-jsonl prints every event as one JSON line on stdout,
+moving all other output to stderr. Both record events skipped by --filter and
+--filter-header too, and work with or without --forward-to, which turns the CLI into a
+pure capture tool.
+
+Targets may be Unix domain sockets: unix:///path/to/app.sock, optionally followed by
+the request path (unix:///path/to/app.sock:/webhook). HTTPS targets with self-signed
+certificates can be trusted with --ca-cert or --insecure, and --client-cert with
+--client-key authenticate to targets that require mTLS.
 
-Example:
-  volley listen --source abc123xyz --forward-to http://localhost:3000/webhook`,
+--source can be repeated to listen to several sources at once, and --project with
+--all-sources listens to every source of a project (--project also lets --source take
+source slugs). Events go to the --forward-to and --exec targets unless --route sends a
+source to its own target (--route stripe=http://localhost:3000/stripe); --route can be
+repeated and matches a source by slug or ingestion ID.
+
+--config reads sources, targets, routes, filters, header rules and the retry policy
+from a YAML file that can be checked into a repository (for this command --config does
+not name the CLI config file). Flags given on the command line win over the file. The
+file is watched while listening: changes to targets, routes, filters, headers and
+retries apply to the next event, a file that does not load keeps the previous settings,
+and changes to the project or sources need a restart.
+
+--daemon starts the listener in the background, detached from the terminal, with its
+output going to a log file in the config directory. Manage it with 'volley daemon
+status|stop|logs|reload'.
+
+--metrics-addr serves Prometheus metrics on /metrics (polls, poll errors, the current
+poll interval, events received, forwarded, failed and skipped, and forward latency)
+together with /healthz and /readyz. /readyz fails while the latest API poll of any
+source failed or before the first one finished; /healthz fails once polls of a source
+have kept failing for a minute.
+
+Examples:
+  volley listen --source abc123xyz --forward-to http://localhost:3000/webhook
+  volley listen --config volley.listen.yaml
+  volley listen --config volley.listen.yaml --daemon
+  volley listen --source abc123xyz --forward-to http://localhost:3000/webhook --metrics-addr :9090
+  volley listen --project shop --all-sources --route stripe=http://localhost:3000/stripe --route github=http://localhost:3000/gh
+  volley listen --source abc123xyz -f http://localhost:3000/webhook -f http://localhost:3001/process
+  volley listen --source abc123xyz --forward-to http://localhost:3000/webhook --resume
+  volley listen --source abc123xyz --forward-to http://localhost:3000/webhook --connection stripe-staging
+  volley listen --source abc123xyz --forward-to http://localhost:3000/webhook --mode direct
+  volley listen --source abc123xyz --forward-to http://localhost:3000/webhook --since 15m
+  volley listen --source abc123xyz --forward-to http://localhost:3000/webhook --since 2026-10-17T10:00:00Z
+  volley listen --source abc123xyz --forward-to http://localhost:3000/webhook --last 20
+  volley listen --source abc123xyz --forward-to http://localhost:3000/webhook --retries 5 --retry-on 5xx,429
+  volley listen --source abc123xyz --forward-to http://localhost:3000/webhook --verify stripe --secret whsec_...
+  volley listen --source abc123xyz --forward-to http://localhost:3000/webhook --resign stripe --resign-secret whsec_local...
+  volley listen --source abc123xyz --forward-to http://localhost:3000/webhook --filter 'type in [invoice.paid, invoice.failed]'
+  volley listen --source abc123xyz --exec 'python handle.py'
+  volley listen --source abc123xyz --stdout-jsonl | jq .raw_body
+  volley listen --source abc123xyz --forward-to unix:///var/run/app.sock:/webhook
+  volley listen --source abc123xyz --forward-to https://localhost:8443/webhook --ca-cert ./dev-ca.pem`,
 	RunE: runListen,
 }
 
 func init() {
-	listenCmd.Flags().StringVarP(&forwardURL, "forward-to", "f", "", "URL to forward webhooks to (required)")
-	listenCmd.Flags().StringVarP(&sourceID, "source", "s", "", "Source ingestion ID (required)")
-	listenCmd.MarkFlagRequired("forward-to")
-	listenCmd.MarkFlagRequired("source")
+	listenCmd.Flags().StringArrayVarP(&forwardURLs, "forward-to", "f", nil, "URL to forward webhooks to (repeatable); one of --forward-to, --exec, --route, --output-dir or --stdout-jsonl is required")
+	listenCmd.Flags().StringArrayVarP(&sourceIDs, "source", "s", nil, "Source ingestion ID (repeatable)")
+	listenCmd.Flags().StringVar(&projectRef, "project", "", "Project ID or name to look sources up in")
+	listenCmd.Flags().BoolVar(&allSources, "all-sources", false, "Listen to every source of --project")
+	listenCmd.Flags().StringArrayVar(&routeSpecs, "route", nil, "Forward events of one source (slug or ingestion ID) to its own URL, e.g. stripe=http://localhost:3000/stripe (repeatable)")
+	listenCmd.Flags().StringVar(&listenConfigFile, "config", "", "YAML file with sources, targets, filters, header rules and retry policy; reloaded when it changes")
+	listenCmd.Flags().BoolVar(&resume, "resume", false, "Resume from the last forwarded event, backfilling anything missed while stopped")
+	listenCmd.Flags().StringVar(&since, "since", "", "Replay events since a duration ago (e.g. 15m) or an RFC3339 timestamp")
+	listenCmd.Flags().IntVar(&last, "last", 0, "Replay the last N events before listening")
+	listenCmd.Flags().StringVar(&transport, "transport", "auto", "How to receive events: auto (stream, falling back to polling), stream or poll")
+	listenCmd.Flags().StringVar(&listenMode, "mode", "auto", "How to receive events: auto (connection mode when the source has connections), direct or connection")
+	listenCmd.Flags().StringArrayVar(&connRefs, "connection", nil, "Connection ID or name to listen on in connection mode (repeatable, one per source)")
+	listenCmd.Flags().IntVar(&retries, "retries", 0, "Number of times to retry a failed forward")
+	listenCmd.Flags().DurationVar(&retryBackoff, "retry-backoff", time.Second, "Initial delay between retries, doubled on every attempt")
+	listenCmd.Flags().StringSliceVar(&retryOn, "retry-on", []string{"5xx", "429"}, "Status codes or classes to retry (refused connections and timeouts are always retried)")
+	listenCmd.Flags().BoolVar(&useDLQ, "dlq", true, "Save events that could not be forwarded to the local dead-letter queue")
+	listenCmd.Flags().IntVar(&concurrency, "concurrency", 1, "Number of events forwarded in parallel")
+	listenCmd.Flags().BoolVar(&ordered, "ordered", false, "Deliver events from the same source one at a time in created_at order")
+	listenCmd.Flags().IntVar(&dedupeSize, "dedupe-size", 10000, "Maximum number of event IDs remembered to avoid duplicate deliveries")
+	listenCmd.Flags().DurationVar(&dedupeWindow, "dedupe-window", 24*time.Hour, "How long event IDs are remembered (0 keeps them until evicted by size)")
+	listenCmd.Flags().StringVar(&dedupeFile, "dedupe-file", "", "File to persist remembered event IDs in (default with --resume: dedupe.log in the config directory)")
+	listenCmd.Flags().BoolVar(&preserveRequest, "preserve-request", false, "Forward with the original HTTP method and append the original sub-path and query string to the target URL")
+	listenCmd.Flags().BoolVar(&printResponse, "print-response", false, "Print the headers and body the local endpoint responded with")
+	listenCmd.Flags().BoolVar(&daemonize, "daemon", false, "Keep listening in the background; manage it with 'volley daemon'")
+	listenCmd.Flags().BoolVar(&useUI, "ui", false, "Show an interactive terminal dashboard of events and local responses")
+	listenCmd.Flags().StringVar(&metricsAddr, "metrics-addr", "", "Serve Prometheus metrics, /healthz and /readyz on this address (e.g. :9090)")
+	listenCmd.Flags().StringVar(&inspectAddr, "inspect", "", "Serve a web inspector of received events on this address (e.g. :4040, bound to 127.0.0.1)")
+	listenCmd.Flags().StringVar(&verifyProvider, "verify", "", "Verify signatures before forwarding: "+strings.Join(signature.Providers(), ", "))
+	listenCmd.Flags().StringVar(&verifySecret, "secret", "", "Signing secret for --verify (default: $VOLLEY_WEBHOOK_SECRET)")
+	listenCmd.Flags().StringVar(&signatureHeader, "signature-header", signature.DefaultHeader, "Header holding the signature for --verify and --resign hmac-sha256")
+	listenCmd.Flags().BoolVar(&dropInvalid, "drop-invalid", false, "Skip events whose signature is invalid instead of forwarding them")
+	listenCmd.Flags().StringVar(&resignProvider, "resign", "", "Re-sign forwarded requests with a local secret: "+strings.Join(signature.Providers(), ", "))
+	listenCmd.Flags().StringVar(&resignSecret, "resign-secret", "", "Signing secret for --resign (default: $VOLLEY_RESIGN_SECRET)")
+	listenCmd.Flags().StringArrayVar(&bodyFilters, "filter", nil, "Only forward events whose JSON body matches, e.g. 'type in [invoice.paid, invoice.failed]' (repeatable)")
+	listenCmd.Flags().StringArrayVar(&headerFilters, "filter-header", nil, "Only forward events with a matching header, e.g. 'X-GitHub-Event=push' or 'X-GitHub-Event!=ping' (repeatable)")
+	listenCmd.Flags().StringArrayVar(&addHeaders, "add-header", nil, "Set a header on forwarded requests, e.g. 'Authorization: Bearer dev' (repeatable)")
+	listenCmd.Flags().StringArrayVar(&removeHeaders, "remove-header", nil, "Remove a header from forwarded requests (repeatable)")
+	listenCmd.Flags().StringArrayVar(&renameHeaders, "rename-header", nil, "Rename an original header, e.g. 'X-Forwarded-Host=X-Original-Host' (repeatable)")
+	listenCmd.Flags().StringVar(&transformCmd, "transform", "", "Command that reshapes each event: reads the event JSON on stdin and prints the event to forward")
+	listenCmd.Flags().StringArrayVar(&execCommands, "exec", nil, "Deliver events to a local command instead of a URL (repeatable)")
+	listenCmd.Flags().BoolVar(&execPersistent, "exec-persistent", false, "Keep one --exec process running that handles events as newline-delimited JSON")
+	listenCmd.Flags().BoolVar(&insecureTLS, "insecure", false, "Skip TLS certificate verification for HTTPS targets")
+	listenCmd.Flags().StringVar(&caCert, "ca-cert", "", "PEM file with CA certificates to trust for HTTPS targets")
+	listenCmd.Flags().StringVar(&clientCert, "client-cert", "", "PEM client certificate for HTTPS targets that require mTLS")
+	listenCmd.Flags().StringVar(&clientKey, "client-key", "", "PEM private key of --client-cert")
+	listenCmd.Flags().StringVar(&outputDir, "output-dir", "", "Write every received event, filtered or not, to this directory (body plus headers sidecar)")
+	listenCmd.Flags().BoolVar(&stdoutJSONL, "stdout-jsonl", false, "Print every received event, filtered or not, as one JSON line on stdout; other output goes to stderr")
+	listenCmd.MarkFlagsMutuallyExclusive("stdout-jsonl", "ui")
+	listenCmd.MarkFlagsMutuallyExclusive("daemon", "ui")
+	listenCmd.MarkFlagsMutuallyExclusive("daemon", "stdout-jsonl")
+	listenCmd.MarkFlagsMutuallyExclusive("source", "all-sources")
+	listenCmd.MarkFlagsMutuallyExclusive("since", "last")
 
 	rootCmd.AddCommand(listenCmd)
 }
@@ -48,6 +361,132 @@ func runListen(cmd *cobra.Command, args []string) error {
 	if cfg.Token == "" {
 		return fmt.Errorf("not authenticated. Run 'volley login' first")
 	}
+	if daemonize {
+		return startDaemon()
+	}
+	if runningAsDaemon() {
+		// Nobody reads the usage in the log; the error alone says what went wrong
+		cmd.SilenceUsage = true
+	}
+
+	var sinceTime time.Time
+	if since != "" {
+		var err error
+		sinceTime, err = parseSince(since, time.Now())
+		if err != nil {
+			return err
+		}
+	}
+	if last < 0 {
+		return fmt.Errorf("--last must be a positive number")
+	}
+	if concurrency < 1 {
+		return fmt.Errorf("--concurrency must be at least 1")
+	}
+	if dedupeSize < minDedupeSize {
+		return fmt.Errorf("--dedupe-size must be at least %d", minDedupeSize)
+	}
+	if dedupeWindow < 0 {
+		return fmt.Errorf("--dedupe-window must not be negative")
+	}
+
+	// Settings from --config apply where no flag was given on the command line
+	var spec *listenSpec
+	changed := cmd.Flags().Changed
+	if listenConfigFile != "" {
+		var err error
+		spec, err = loadListenSpec(listenConfigFile)
+		if err != nil {
+			return err
+		}
+		if !changed("project") {
+			projectRef = spec.Project
+		}
+		if !changed("all-sources") && !changed("source") {
+			allSources = spec.AllSources
+		}
+		if !changed("source") && !changed("all-sources") {
+			sourceIDs = spec.Sources
+		}
+	}
+	if len(sourceIDs) == 0 && !allSources {
+		return fmt.Errorf("one of --source or --all-sources is required")
+	}
+	if len(sourceIDs) > 0 && allSources {
+		return fmt.Errorf("--source and --all-sources cannot be used together")
+	}
+	rules, err := buildListenRules(spec, changed)
+	if err != nil {
+		return err
+	}
+	switch transport {
+	case "auto", "stream", "poll":
+	default:
+		return fmt.Errorf("invalid --transport %q: must be auto, stream or poll", transport)
+	}
+	switch listenMode {
+	case "auto", "direct", "connection":
+	default:
+		return fmt.Errorf("invalid --mode %q: must be auto, direct or connection", listenMode)
+	}
+	if listenMode == "direct" && len(connRefs) > 0 {
+		return fmt.Errorf("--connection cannot be used with --mode direct")
+	}
+	var verifier *signature.Verifier
+	if verifyProvider != "" {
+		secret := verifySecret
+		if secret == "" {
+			secret = os.Getenv("VOLLEY_WEBHOOK_SECRET")
+		}
+		verifier, err = signature.NewVerifier(verifyProvider, secret, signatureHeader)
+		if err != nil {
+			return fmt.Errorf("invalid --verify: %w", err)
+		}
+	} else if dropInvalid {
+		return fmt.Errorf("--drop-invalid requires --verify")
+	}
+	var signer *signature.Signer
+	if resignProvider != "" {
+		secret := resignSecret
+		if secret == "" {
+			secret = os.Getenv("VOLLEY_RESIGN_SECRET")
+		}
+		signer, err = signature.NewSigner(resignProvider, secret, signatureHeader)
+		if err != nil {
+			return fmt.Errorf("invalid --resign: %w", err)
+		}
+	}
+	if execPersistent && len(execCommands) == 0 {
+		return fmt.Errorf("--exec-persistent requires --exec")
+	}
+	if transformCmd != "" && signer == nil {
+		fmt.Fprintln(os.Stderr, "Warning: --transform changes events after they were signed; signatures will not verify unless you also use --resign")
+	}
+	tlsConfig, err := tlsOptions{insecure: insecureTLS, caCert: caCert, clientCert: clientCert, clientKey: clientKey}.config()
+	if err != nil {
+		return err
+	}
+	var sinks []eventSink
+	if outputDir != "" {
+		sink, err := newDirSink(outputDir)
+		if err != nil {
+			return err
+		}
+		sinks = append(sinks, sink)
+	}
+	if len(rules.targets) == 0 && len(rules.routes) == 0 && len(sinks) == 0 {
+		return fmt.Errorf("one of --forward-to, --exec, --route, --output-dir or --stdout-jsonl is required")
+	}
+	if stdoutJSONL {
+		// Keep stdout for events only so it can be piped
+		sinks = append(sinks, &jsonlSink{w: os.Stdout})
+		stdout := os.Stdout
+		os.Stdout = os.Stderr
+		defer func() { os.Stdout = stdout }()
+	}
+	if useUI && (!term.IsTerminal(int(os.Stdin.Fd())) || !term.IsTerminal(int(os.Stdout.Fd()))) {
+		return fmt.Errorf("--ui requires an interactive terminal")
+	}
 
 	// Use API URL from flag, config, or default
 	apiURL := viper.GetString("api_url")
@@ -61,49 +500,264 @@ func runListen(cmd *cobra.Command, args []string) error {
 	apiClient := api.NewClient(apiURL)
 	apiClient.SetToken(cfg.Token)
 
-	// Get source details to find source ID and project ID
-	sourceWithProject, err := apiClient.GetSourceByIngestionIDWithProject(sourceID)
+	// Record when we started listening - only forward events created after this. Taken
+	// before looking up sources and connections, which may wait for the user to pick one.
+	startTime := time.Now()
+
+	sources, err := resolveSources(apiClient, sourceIDs, projectRef, allSources)
 	if err != nil {
-		return fmt.Errorf("failed to get source: %w", err)
+		return err
 	}
-	source := sourceWithProject.Source
-	projectID := sourceWithProject.ProjectID
-
-	// Get connections for this source to determine which mode to use
-	connections, err := apiClient.GetConnectionsBySource(source.ID)
+	// A signing secret belongs to one provider account, so it cannot apply to every source
+	if len(sources) > 1 && (verifier != nil || signer != nil) {
+		return fmt.Errorf("--verify and --resign use one secret and can only be used when listening to a single source (got %d sources)", len(sources))
+	}
+	targets, err := rules.sourceTargets(sources, len(sinks) > 0)
 	if err != nil {
-		return fmt.Errorf("failed to get connections: %w", err)
+		return err
 	}
+	for _, src := range sources {
+		src.targets = targets[src.id]
 
-	// Record when we started listening - only forward events created after this
-	startTime := time.Now()
-	
-	// Determine mode: use connection-based polling if connections exist, otherwise direct event polling
-	useConnectionMode := len(connections) > 0
-	var connectionID uint64
-	if useConnectionMode {
-		connectionID = connections[0].ID
-		fmt.Printf("Ready! Forwarding webhooks from source '%s' to %s\n", sourceID, forwardURL)
-		fmt.Printf("Source: %s (ID: %d)\n", source.Slug, source.ID)
-		fmt.Printf("Connection: %s (ID: %d)\n", connections[0].Name, connectionID)
+		// Direct event mode needs no connection; otherwise poll the connection's delivery attempts
+		if listenMode == "direct" {
+			continue
+		}
+		connections, err := apiClient.GetConnectionsBySource(src.id)
+		if err != nil {
+			return fmt.Errorf("failed to get connections: %w", err)
+		}
+		src.connection, err = chooseConnection(src, connections, connRefs, listenMode == "connection")
+		if err != nil {
+			return err
+		}
+		if src.connection != nil && transport == "stream" {
+			return fmt.Errorf("--transport stream is only available in direct event mode (source '%s' listens on a connection; use --mode direct)", src.ingestionID)
+		}
+	}
+	if unused := unusedConnectionRefs(connRefs, sources); len(unused) > 0 {
+		return fmt.Errorf("--connection %s does not match any connection of the sources", strings.Join(unused, ", "))
+	}
+
+	l := &listener{
+		apiClient: apiClient,
+		sources:   make(map[uint64]*listenSource, len(sources)),
+		retry:     rules.retry,
+		forward: forwardOptions{
+			preserveRequest: preserveRequest,
+			signer:          signer,
+			headers:         rules.headers,
+			clients:         newForwardClients(tlsConfig),
+		},
+		resume: resume,
+
+		history:       newHistory(),
+		metrics:       newListenMetrics(),
+		printResponse: printResponse,
+
+		filters:     rules.filters,
+		sinks:       sinks,
+		transform:   transformCmd,
+		verifier:    verifier,
+		dropInvalid: dropInvalid,
+	}
+	for _, src := range sources {
+		l.sources[src.id] = src
+	}
+	if spec != nil {
+		l.config = &configReloader{l: l, path: listenConfigFile, spec: spec, changed: changed}
+	}
+
+	// Persist remembered IDs when resuming so a restart never re-delivers an event
+	l.dedupeWindow = dedupeWindow
+	if dedupeFile == "" && resume {
+		dedupeFile = filepath.Join(config.Dir(), "dedupe.log")
+	}
+	if dedupeFile != "" {
+		l.seen, err = dedupe.Open(dedupeFile, dedupeSize, dedupeWindow)
+		if err != nil {
+			return err
+		}
 	} else {
-		fmt.Printf("Ready! Forwarding webhooks from source '%s' to %s\n", sourceID, forwardURL)
-		fmt.Printf("Source: %s (ID: %d)\n", source.Slug, source.ID)
-		fmt.Printf("Mode: Direct event polling (no connection required)\n")
+		l.seen = dedupe.New(dedupeSize, dedupeWindow)
+	}
+	defer l.seen.Close()
+
+	if useDLQ {
+		l.dlq, err = dlq.Open(dlq.DefaultDir())
+		if err != nil {
+			return err
+		}
 	}
-	fmt.Println("Press Ctrl+C to stop")
 
-	// Handle graceful shutdown
+	for _, src := range sources {
+		destinations := append([]string(nil), src.targets...)
+		if outputDir != "" {
+			destinations = append(destinations, outputDir)
+		}
+		if stdoutJSONL {
+			destinations = append(destinations, "stdout (JSONL)")
+		}
+		fmt.Printf("Ready! Forwarding webhooks from source '%s' to %s\n", src.ingestionID, strings.Join(destinations, ", "))
+		fmt.Printf("Source: %s (ID: %d)\n", src.slug, src.id)
+		if src.connection != nil {
+			fmt.Printf("Connection: %s (ID: %d)\n", src.connection.Name, src.connection.ID)
+		} else {
+			fmt.Printf("Mode: Direct event polling (no connection required)\n")
+		}
+	}
+
+	// Handle graceful shutdown: the first signal stops polling and lets queued deliveries
+	// finish, a second one aborts them
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
 
-	// Track forwarded event IDs to avoid duplicates
-	forwardedEventIDs := make(map[string]bool)
-	
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	deliverCtx, cancelDeliveries := context.WithCancel(context.Background())
+	defer cancelDeliveries()
+
+	go func() {
+		<-sigChan
+		cancel()
+		<-sigChan
+		cancelDeliveries()
+	}()
+
+	l.dispatcher = newDispatcher(deliverCtx, concurrency, ordered, l.deliver)
+
+	if inspectAddr != "" {
+		inspector, err := startInspector(l, deliverCtx, inspectAddr)
+		if err != nil {
+			return err
+		}
+		defer inspector.stop()
+	}
+
+	if metricsAddr != "" {
+		metricsServer, err := startMetricsServer(l, metricsAddr)
+		if err != nil {
+			return err
+		}
+		defer metricsServer.stop()
+	}
+
+	// The dashboard is stopped only after the shutdown below, so draining stays visible
+	if useUI {
+		ui, err := startListenUI(l, deliverCtx, sigChan)
+		if err != nil {
+			return err
+		}
+		defer ui.stop()
+	}
+
+	// In the background the listener is managed through a control socket, which stays up
+	// until queued deliveries are drained
+	if runningAsDaemon() {
+		control, err := startControlServer(l, sigChan)
+		if err != nil {
+			return err
+		}
+		defer control.stop()
+	}
+	defer l.shutdown()
+
+	// Load checkpoints even when --since or --last is given so replaying older
+	// events never moves them backwards
+	if resume {
+		for _, src := range sources {
+			src.checkpoint, err = config.LoadCheckpoint(src.id)
+			if err != nil {
+				return fmt.Errorf("failed to load checkpoint: %w", err)
+			}
+		}
+	}
+
+	if l.config != nil {
+		go l.config.watch(ctx)
+	}
+
+	fmt.Println("Press Ctrl+C to stop")
+
+	// Every source catches up and polls on its own; the first error stops all of them
+	errs := make(chan error, len(sources))
+	for _, src := range sources {
+		go func() {
+			errs <- l.run(ctx, src, startTime, sinceTime)
+		}()
+	}
+	var firstErr error
+	for range sources {
+		if err := <-errs; err != nil && firstErr == nil {
+			firstErr = err
+			cancel()
+		}
+	}
+	return firstErr
+}
+
+// run catches up on the history of one source and then forwards its live events until
+// ctx is cancelled
+func (l *listener) run(ctx context.Context, src *listenSource, startTime, sinceTime time.Time) error {
+	// Catch up on history before switching to live polling. An explicit --since or --last
+	// takes precedence over the stored checkpoint.
+	switch {
+	case !sinceTime.IsZero():
+		fmt.Printf("Replaying events from %s since %s\n", src.slug, sinceTime.Format(time.RFC3339))
+		count, err := l.backfill(ctx, src, sinceTime)
+		if err != nil {
+			return fmt.Errorf("failed to backfill events: %w", err)
+		}
+		fmt.Printf("Backfilled %d event(s) from %s\n", count, src.slug)
+	case last > 0:
+		fmt.Printf("Replaying the last %d event(s) from %s\n", last, src.slug)
+		count, err := l.backfillLast(ctx, src, last)
+		if err != nil {
+			return fmt.Errorf("failed to backfill events: %w", err)
+		}
+		fmt.Printf("Backfilled %d event(s) from %s\n", count, src.slug)
+	case l.resume:
+		if cp := src.checkpoint; cp != nil {
+			if cp.EventID != "" {
+				l.markHandled(cp.EventID)
+				fmt.Printf("Resuming %s after event %s (%s)\n", src.slug, cp.EventID, cp.CreatedAt.Format(time.RFC3339))
+			} else {
+				fmt.Printf("Resuming %s from %s\n", src.slug, cp.CreatedAt.Format(time.RFC3339))
+			}
+			count, err := l.backfill(ctx, src, cp.CreatedAt)
+			if err != nil {
+				return fmt.Errorf("failed to backfill events: %w", err)
+			}
+			fmt.Printf("Backfilled %d event(s) from %s\n", count, src.slug)
+		} else {
+			// Record the start right away: a run that forwards nothing would otherwise
+			// leave no checkpoint and the next one would skip what arrived in between
+			fmt.Printf("No checkpoint found for source %s, starting from now\n", src.slug)
+			cp := config.Checkpoint{CreatedAt: startTime}
+			if err := config.SaveCheckpoint(src.id, cp); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: failed to save checkpoint: %v\n", err)
+			} else {
+				l.mu.Lock()
+				src.checkpoint = &cp
+				l.mu.Unlock()
+			}
+		}
+	}
+
+	// Prefer the push stream in direct mode. Polling keeps running until the stream
+	// connects and takes over again whenever it drops.
+	streamEvents := make(chan api.Event)
+	streamStatus := make(chan error)
+	streaming := false
+	if src.connection == nil && transport != "poll" {
+		go l.runStream(ctx, src, streamEvents, streamStatus)
+	}
+
 	// Adaptive polling: start with 2s, increase to 5s if no events found (optimization)
 	pollInterval := 2 * time.Second
 	noEventsCount := 0
 	const maxNoEventsBeforeSlowdown = 10 // Slow down after 10 polls with no events
+	l.metrics.pollInterval.Set(pollInterval.Seconds(), src.slug)
 
 	// Poll for new events
 	ticker := time.NewTicker(pollInterval)
@@ -111,20 +765,52 @@ func runListen(cmd *cobra.Command, args []string) error {
 
 	for {
 		select {
-		case <-sigChan:
-			fmt.Println("\n✓ Shutting down...")
+		case <-ctx.Done():
 			return nil
+		case err := <-streamStatus:
+			switch {
+			case err == nil:
+				streaming = true
+				l.metrics.setHealth(src.slug, nil)
+				fmt.Printf("✓ Connected to event stream for %s\n", src.slug)
+			case errors.Is(err, api.ErrStreamUnsupported):
+				if transport == "stream" {
+					return err
+				}
+				if viper.GetBool("verbose") {
+					fmt.Fprintln(os.Stderr, "Server does not offer event streaming, using polling")
+				}
+			default:
+				streaming = false
+				fmt.Fprintf(os.Stderr, "Warning: event stream disconnected, polling until it reconnects: %v\n", err)
+			}
+		case event := <-streamEvents:
+			if l.seen.Seen(event.EventID) {
+				continue
+			}
+			l.seen.Mark(event.EventID)
+			event.SourceID = src.id
+			if l.skip(&event) {
+				continue
+			}
+			l.submit(ctx, &event)
 		case <-ticker.C:
+			// The stream delivers events as they arrive, no need to poll
+			if streaming {
+				continue
+			}
+
 			var eventsProcessed int
 			var err error
 			
-			if useConnectionMode {
+			if src.connection != nil {
 				// Mode 1: Connection-based polling (backward compatible)
-				eventsProcessed, err = pollConnectionMode(apiClient, connectionID, projectID, startTime, forwardedEventIDs, forwardURL)
+				eventsProcessed, err = l.pollConnectionMode(ctx, src, l.pollSince(startTime))
 			} else {
 				// Mode 2: Direct event polling (new, simplified flow)
-				eventsProcessed, err = pollDirectEventMode(apiClient, projectID, source.ID, startTime, forwardedEventIDs, forwardURL)
+				eventsProcessed, err = l.pollDirectEventMode(ctx, src, l.pollSince(startTime))
 			}
+			l.metrics.pollDone(src.slug, err)
 			
 			if err != nil {
 				if viper.GetBool("verbose") {
@@ -142,6 +828,7 @@ func runListen(cmd *cobra.Command, args []string) error {
 					ticker.Stop()
 					pollInterval = 2 * time.Second
 					ticker = time.NewTicker(pollInterval)
+					l.metrics.pollInterval.Set(pollInterval.Seconds(), src.slug)
 				}
 			} else {
 				noEventsCount++
@@ -150,6 +837,7 @@ func runListen(cmd *cobra.Command, args []string) error {
 					ticker.Stop()
 					pollInterval = 5 * time.Second
 					ticker = time.NewTicker(pollInterval)
+					l.metrics.pollInterval.Set(pollInterval.Seconds(), src.slug)
 					if viper.GetBool("verbose") {
 						fmt.Fprintf(os.Stderr, "No events detected, slowing polling to %v\n", pollInterval)
 					}
@@ -160,9 +848,9 @@ func runListen(cmd *cobra.Command, args []string) error {
 }
 
 // pollConnectionMode polls using delivery attempts (backward compatible mode)
-func pollConnectionMode(apiClient *api.Client, connectionID uint64, projectID uint64, startTime time.Time, forwardedEventIDs map[string]bool, forwardURL string) (int, error) {
+func (l *listener) pollConnectionMode(ctx context.Context, src *listenSource, startTime time.Time) (int, error) {
 	// Get recent delivery attempts for this connection
-	attempts, err := apiClient.GetDeliveryAttempts(connectionID, 20)
+	attempts, err := l.apiClient.GetDeliveryAttempts(src.connection.ID, 20)
 	if err != nil {
 		return 0, err
 	}
@@ -174,21 +862,21 @@ func pollConnectionMode(apiClient *api.Client, connectionID uint64, projectID ui
 		attempt := attempts[i]
 
 		// Skip if we've already processed this event
-		if forwardedEventIDs[attempt.EventID] {
+		if l.seen.Seen(attempt.EventID) {
 			continue
 		}
 
 		// Check if this attempt is new (created after we started)
 		if attempt.CreatedAt == "" {
 			// No timestamp - skip it to be safe
-			forwardedEventIDs[attempt.EventID] = true
+			l.markHandled(attempt.EventID)
 			continue
 		}
 
 		createdAt, err := time.Parse(time.RFC3339, attempt.CreatedAt)
 		if err != nil {
 			// Can't parse timestamp - skip it
-			forwardedEventIDs[attempt.EventID] = true
+			l.markHandled(attempt.EventID)
 			if viper.GetBool("verbose") {
 				fmt.Fprintf(os.Stderr, "Warning: failed to parse timestamp for event %s: %v\n", attempt.EventID, err)
 			}
@@ -197,19 +885,19 @@ func pollConnectionMode(apiClient *api.Client, connectionID uint64, projectID ui
 
 		// Skip old events (created before we started listening)
 		if !createdAt.After(startTime) {
-			forwardedEventIDs[attempt.EventID] = true
+			l.markHandled(attempt.EventID)
 			continue
 		}
 
-		// Mark as processed immediately to avoid duplicates
-		forwardedEventIDs[attempt.EventID] = true
+		// Mark as pending immediately to avoid duplicates; deliver records it once handled
+		l.seen.Mark(attempt.EventID)
 
 		// Query event directly by event_id with retries
 		// New events might take a moment to be indexed
 		var event *api.Event
 		maxRetries := 5
 		for retry := 0; retry < maxRetries; retry++ {
-			event, err = apiClient.GetEvent(attempt.EventID, projectID)
+			event, err = l.apiClient.GetEvent(attempt.EventID, src.projectID)
 			if err == nil {
 				break
 			}
@@ -228,11 +916,13 @@ func pollConnectionMode(apiClient *api.Client, connectionID uint64, projectID ui
 			continue
 		}
 
-		// Forward to local endpoint
-		if err := forwardEvent(event, forwardURL); err != nil {
-			fmt.Fprintf(os.Stderr, "✗ Failed to forward event %s: %v\n", attempt.EventID, err)
-		} else {
-			fmt.Printf("✓ Forwarded event %s -> %s\n", attempt.EventID, forwardURL)
+		event.SourceID = src.id
+		if l.skip(event) {
+			continue
+		}
+
+		// Queue for delivery to every local endpoint
+		if l.submit(ctx, event) {
 			eventsProcessed++
 		}
 	}
@@ -242,40 +932,42 @@ func pollConnectionMode(apiClient *api.Client, connectionID uint64, projectID ui
 
 // pollDirectEventMode polls events directly from source (simplified mode, no connection required)
 // CRITICAL: Events are forwarded with exact headers and raw body to preserve webhook signature validation
-func pollDirectEventMode(apiClient *api.Client, projectID uint64, sourceID uint64, startTime time.Time, forwardedEventIDs map[string]bool, forwardURL string) (int, error) {
+func (l *listener) pollDirectEventMode(ctx context.Context, src *listenSource, startTime time.Time) (int, error) {
 	// Use optimized API call with source_id and start_time filtering (server-side filtering is more efficient)
-	events, err := apiClient.GetEventsBySource(projectID, sourceID, 50, &startTime)
+	events, err := l.apiClient.GetEventsBySource(src.projectID, src.id, 50, &startTime, nil)
 	if err != nil {
 		return 0, err
 	}
 
 	eventsProcessed := 0
 
-	// Process events in reverse order (newest first)
+	// Events come back newest first; process them oldest first
 	for i := len(events) - 1; i >= 0; i-- {
 		event := events[i]
 
 		// Skip if we've already processed this event
-		if forwardedEventIDs[event.EventID] {
+		if l.seen.Seen(event.EventID) {
 			continue
 		}
 
 		// Double-check timestamp (server-side filtering should handle this, but be safe)
 		if !event.CreatedAt.After(startTime) {
-			forwardedEventIDs[event.EventID] = true
+			l.markHandled(event.EventID)
 			continue
 		}
 
-		// Mark as processed immediately to avoid duplicates
-		forwardedEventIDs[event.EventID] = true
+		// Mark as pending immediately to avoid duplicates; deliver records it once handled
+		l.seen.Mark(event.EventID)
+
+		event.SourceID = src.id
+		if l.skip(&event) {
+			continue
+		}
 
 		// Forward to local endpoint
 		// Note: No retries needed here since events are already in DB (more efficient than connection mode)
 		// The forwardEvent function preserves exact headers and raw body for signature validation
-		if err := forwardEvent(&event, forwardURL); err != nil {
-			fmt.Fprintf(os.Stderr, "✗ Failed to forward event %s: %v\n", event.EventID, err)
-		} else {
-			fmt.Printf("✓ Forwarded event %s -> %s\n", event.EventID, forwardURL)
+		if l.submit(ctx, &event) {
 			eventsProcessed++
 		}
 	}
@@ -283,50 +975,623 @@ func pollDirectEventMode(apiClient *api.Client, projectID uint64, sourceID uint6
 	return eventsProcessed, nil
 }
 
-func forwardEvent(event *api.Event, targetURL string) error {
-	client := &http.Client{Timeout: 10 * time.Second}
+// backfill queues every event created at or after since, oldest first. It returns the
+// number of events queued.
+func (l *listener) backfill(ctx context.Context, src *listenSource, since time.Time) (int, error) {
+	events, err := l.fetchHistory(src, &since, 0)
+	if err != nil {
+		return 0, err
+	}
+	return l.queueHistory(ctx, src, events), nil
+}
 
-	// Forward raw body as-is to preserve exact bytes (important for signature verification)
-	// Re-encoding JSON would change the exact bytes and break webhook signatures
-	body := []byte(event.RawBody)
+// backfillLast queues the n most recent events, oldest first
+func (l *listener) backfillLast(ctx context.Context, src *listenSource, n int) (int, error) {
+	events, err := l.fetchHistory(src, nil, n)
+	if err != nil {
+		return 0, err
+	}
+	return l.queueHistory(ctx, src, events), nil
+}
+
+// fetchHistory pages backwards through the events of a source, which GetEventsBySource
+// returns newest first, until it reaches events older than since or has collected n
+// events. since may be nil and n 0 for no limit. The result is newest first.
+func (l *listener) fetchHistory(src *listenSource, since *time.Time, n int) ([]api.Event, error) {
+	var history []api.Event
+	collected := make(map[string]bool)
+	var end *time.Time
+	for {
+		events, err := l.apiClient.GetEventsBySource(src.projectID, src.id, backfillPageSize, since, end)
+		if err != nil {
+			return history, err
+		}
+
+		for _, event := range events {
+			if since != nil && event.CreatedAt.Before(*since) {
+				return history, nil
+			}
+			// The page boundary may be inclusive and repeat events of the previous page
+			if collected[event.EventID] {
+				continue
+			}
+			collected[event.EventID] = true
+			history = append(history, event)
+			if n > 0 && len(history) >= n {
+				return history, nil
+			}
+		}
+
+		// Stop once a page comes back short or the end cursor stops moving back
+		if len(events) < backfillPageSize {
+			return history, nil
+		}
+		oldest := events[len(events)-1].CreatedAt
+		if end != nil && !oldest.Before(*end) {
+			fmt.Fprintf(os.Stderr, "Warning: could not page past events of %s created at %s, replaying the %d event(s) found\n", src.slug, oldest.Format(time.RFC3339), len(history))
+			return history, nil
+		}
+		end = &oldest
+	}
+}
+
+// queueHistory queues events fetched newest first for delivery, oldest first. It
+// returns the number of events queued.
+func (l *listener) queueHistory(ctx context.Context, src *listenSource, events []api.Event) int {
+	forwarded := 0
+	for i := len(events) - 1; i >= 0; i-- {
+		event := &events[i]
+		if l.seen.Seen(event.EventID) {
+			continue
+		}
+		l.seen.Mark(event.EventID)
+		event.SourceID = src.id
+		if l.skip(event) {
+			continue
+		}
+		if !l.submit(ctx, event) {
+			break
+		}
+		forwarded++
+	}
+	return forwarded
+}
+
+// parseSince interprets a --since value as either a duration before now or an RFC3339 timestamp
+func parseSince(value string, now time.Time) (time.Time, error) {
+	if d, err := time.ParseDuration(value); err == nil {
+		if d < 0 {
+			return time.Time{}, fmt.Errorf("--since duration must not be negative")
+		}
+		return now.Add(-d), nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid --since value %q: use a duration like 15m or an RFC3339 timestamp", value)
+	}
+	if t.After(now) {
+		return time.Time{}, fmt.Errorf("--since timestamp %s is in the future", value)
+	}
+	return t, nil
+}
+
+// pollSince returns the start time for the next live poll. Once the listener has run
+// longer than the dedupe window, IDs from its first events are forgotten, so the start
+// moves forward with the window to keep those events from being fetched again.
+func (l *listener) pollSince(startTime time.Time) time.Time {
+	if l.dedupeWindow <= 0 {
+		return startTime
+	}
+	if cutoff := time.Now().Add(-l.dedupeWindow); cutoff.After(startTime) {
+		return cutoff
+	}
+	return startTime
+}
+
+// shutdown stops accepting events and waits for in-flight deliveries to finish
+func (l *listener) shutdown() {
+	fmt.Println("\n✓ Shutting down...")
+	if n := l.dispatcher.inFlight(); n > 0 {
+		fmt.Printf("Waiting for %d in-flight deliveries (press Ctrl+C again to abort)\n", n)
+	}
+	l.dispatcher.close()
+	stopPersistentProcesses()
+}
+
+// skip reports whether an event fails one of the filters, reporting it as skipped and
+// recording it as handled. Every new event passes through here, so it is also counted
+// as received.
+func (l *listener) skip(event *api.Event) bool {
+	source := l.sourceSlug(event)
+	l.metrics.received.Inc(source)
+
+	l.rulesMu.RLock()
+	filters := l.filters
+	l.rulesMu.RUnlock()
+
+	f := filter.First(filters, event.Headers, []byte(event.RawBody))
+	if f == nil {
+		return false
+	}
+	// Sinks record every received event, including the ones that are not forwarded
+	l.capture(event)
+	fmt.Printf("- Skipped event %s (filter: %s)\n", event.EventID, f)
+	l.metrics.skipped.Inc(source, "filter")
+	l.markHandled(event.EventID)
+	return true
+}
+
+// markHandled records an event ID as handled, warning when the dedupe file could not be
+// written
+func (l *listener) markHandled(id string) {
+	if _, err := l.seen.Add(id); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+}
+
+// sourceSlug returns the slug of the source an event came from, for metrics
+func (l *listener) sourceSlug(event *api.Event) string {
+	if src, ok := l.sources[event.SourceID]; ok {
+		return src.slug
+	}
+	return event.SourceSlug
+}
+
+// deliver forwards an event to every target of its source and, in --resume mode, lets
+// the source's checkpoint advance once at least one target accepted it. It runs on the
+// dispatcher's workers.
+func (l *listener) deliver(ctx context.Context, event *api.Event) {
+	// The event is handled or dead-lettered by now; until then its ID was only pending,
+	// so an event lost with the process is fetched again by the next run
+	defer l.markHandled(event.EventID)
+
+	handled := false
+	defer func() { l.finish(event, handled) }()
+
+	l.history.add(event)
+	captured := l.capture(event)
+	if captured && len(l.sinks) > 0 && len(l.targetsFor(event)) == 0 {
+		fmt.Printf("✓ Captured event %s\n", event.EventID)
+	}
+	if !l.checkSignature(event) {
+		return
+	}
+
+	// Without targets the event counts as handled once it was captured
+	if len(l.targetsFor(event)) == 0 {
+		handled = captured
+		return
+	}
+	forwarded, ok := l.applyTransform(ctx, event)
+	handled = ok && l.forwardToTargets(ctx, forwarded) > 0
+}
+
+// submit queues an event for delivery, tracking it as in flight for the --resume checkpoint
+func (l *listener) submit(ctx context.Context, event *api.Event) bool {
+	src, ok := l.sources[event.SourceID]
+	if !ok || !l.resume {
+		return l.dispatcher.submit(ctx, event)
+	}
+
+	l.mu.Lock()
+	if src.inFlight == nil {
+		src.inFlight = make(map[string]time.Time)
+	}
+	src.inFlight[event.EventID] = event.CreatedAt
+	l.mu.Unlock()
+
+	if !l.dispatcher.submit(ctx, event) {
+		l.finish(event, false)
+		return false
+	}
+	return true
+}
+
+// finish records the end of an event's delivery and, in --resume mode, moves the
+// checkpoint to the newest handled event that is older than every event still in
+// flight. Workers finish out of order, so saving the newest handled event right away
+// would skip an older one that is lost with the process.
+func (l *listener) finish(event *api.Event, handled bool) {
+	src, ok := l.sources[event.SourceID]
+	if !ok || !l.resume {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(src.inFlight, event.EventID)
+	if handled {
+		src.delivered = append(src.delivered, config.Checkpoint{EventID: event.EventID, CreatedAt: event.CreatedAt})
+	}
+
+	var oldest time.Time
+	for _, createdAt := range src.inFlight {
+		if oldest.IsZero() || createdAt.Before(oldest) {
+			oldest = createdAt
+		}
+	}
+
+	// Pick the newest delivered event before the oldest one in flight and keep the rest
+	var next *config.Checkpoint
+	waiting := src.delivered[:0]
+	for _, cp := range src.delivered {
+		if !oldest.IsZero() && !cp.CreatedAt.Before(oldest) {
+			waiting = append(waiting, cp)
+			continue
+		}
+		if next == nil || cp.CreatedAt.After(next.CreatedAt) {
+			cp := cp
+			next = &cp
+		}
+	}
+	src.delivered = waiting
+
+	if next == nil || (src.checkpoint != nil && !next.CreatedAt.After(src.checkpoint.CreatedAt)) {
+		return
+	}
+	if err := config.SaveCheckpoint(src.id, *next); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to save checkpoint: %v\n", err)
+		return
+	}
+	src.checkpoint = next
+}
+
+// targetsFor returns the targets events of the event's source are forwarded to
+func (l *listener) targetsFor(event *api.Event) []string {
+	src, ok := l.sources[event.SourceID]
+	if !ok {
+		return nil
+	}
+	l.rulesMu.RLock()
+	defer l.rulesMu.RUnlock()
+	return src.targets
+}
+
+// capture writes an event as received to every sink and reports whether all succeeded
+func (l *listener) capture(event *api.Event) bool {
+	ok := true
+	for _, sink := range l.sinks {
+		if err := sink.write(event); err != nil {
+			fmt.Fprintf(os.Stderr, "✗ Failed to capture event %s: %v\n", event.EventID, err)
+			ok = false
+		}
+	}
+	return ok
+}
 
-	req, err := http.NewRequest("POST", targetURL, bytes.NewReader(body))
+// checkSignature verifies the event's signature in --verify mode and reports whether
+// the event should be forwarded
+func (l *listener) checkSignature(event *api.Event) bool {
+	if l.verifier == nil {
+		return true
+	}
+
+	err := l.verifier.Verify(event.Headers, []byte(event.RawBody))
+	if err == nil {
+		l.history.setSignature(event.EventID, "valid")
+		return true
+	}
+
+	l.history.setSignature(event.EventID, "invalid: "+err.Error())
+	if l.dropInvalid {
+		fmt.Fprintf(os.Stderr, "✗ Skipped event %s: invalid %s signature: %v\n", event.EventID, l.verifier.Provider, err)
+		l.metrics.skipped.Inc(l.sourceSlug(event), "signature")
+		return false
+	}
+	fmt.Fprintf(os.Stderr, "⚠ Event %s has an invalid %s signature: %v\n", event.EventID, l.verifier.Provider, err)
+	return true
+}
+
+// forwardToTargets delivers one event to every target and reports the outcome per target.
+// Every outcome is kept in the history, failed deliveries are also recorded in the
+// dead-letter queue. It returns the number of targets that accepted the event.
+func (l *listener) forwardToTargets(ctx context.Context, event *api.Event) int {
+	l.rulesMu.RLock()
+	opts, policy := l.forward, l.retry
+	l.rulesMu.RUnlock()
+
+	delivered := 0
+	for _, target := range l.targetsFor(event) {
+		resp, err := forwardWithRetry(ctx, event, target, opts, policy)
+		l.history.recordDelivery(event.EventID, newDeliveryRecord(target, resp, err))
+		l.recordForward(event, target, resp, err)
+
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "✗ Failed to forward event %s -> %s: %v%s\n%s", event.EventID, target, err, responseSummary(resp), l.responseDetails(resp))
+			l.deadLetter(event, target, err, "")
+			continue
+		}
+		fmt.Printf("✓ Forwarded event %s -> %s%s\n%s", event.EventID, target, responseSummary(resp), l.responseDetails(resp))
+		delivered++
+	}
+	return delivered
+}
+
+// replay forwards an event from the history to every target again, in the background.
+// The checkpoint is left alone. It reports whether the event was found.
+func (l *listener) replay(ctx context.Context, eventID string) bool {
+	record, ok := l.history.get(eventID)
+	if !ok {
+		return false
+	}
+	event := record.Event
+	fmt.Printf("Re-forwarding event %s\n", event.EventID)
+	go func() {
+		if forwarded, ok := l.applyTransform(ctx, &event); ok {
+			l.forwardToTargets(ctx, forwarded)
+		}
+	}()
+	return true
+}
+
+// applyTransform runs the --transform command on an event and reports whether the
+// result should be forwarded
+func (l *listener) applyTransform(ctx context.Context, event *api.Event) (*api.Event, bool) {
+	if l.transform == "" {
+		return event, true
+	}
+
+	transformed, err := transformEvent(ctx, l.transform, event)
+	if err != nil {
+		// A failed transform fails the delivery to every target, like a failed --exec
+		fmt.Fprintf(os.Stderr, "✗ Failed to transform event %s: %v\n", event.EventID, err)
+		for _, target := range l.targetsFor(event) {
+			l.history.recordDelivery(event.EventID, newDeliveryRecord(target, nil, err))
+			l.recordForward(event, target, nil, err)
+			l.deadLetter(event, target, err, l.transform)
+		}
+		return nil, false
+	}
+	if transformed == nil {
+		fmt.Printf("- Skipped event %s (dropped by transform)\n", event.EventID)
+		l.metrics.skipped.Inc(l.sourceSlug(event), "transform")
+		return nil, false
+	}
+	return transformed, true
+}
+
+// recordForward counts the outcome of delivering an event to a target
+func (l *listener) recordForward(event *api.Event, target string, resp *forwardResponse, err error) {
+	source := l.sourceSlug(event)
+	if resp != nil {
+		l.metrics.forwardLatency.Observe(resp.Latency.Seconds(), source, target)
+	}
 	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
+		l.metrics.failed.Inc(source, target)
+	} else {
+		l.metrics.forwarded.Inc(source, target)
+	}
+}
+
+// responseSummary formats the status code and latency of a response for the delivery line
+func responseSummary(resp *forwardResponse) string {
+	if resp == nil {
+		return ""
+	}
+	// Commands have no status code
+	if resp.StatusCode == 0 {
+		return fmt.Sprintf(" [%v]", resp.Latency.Round(time.Millisecond))
+	}
+	return fmt.Sprintf(" [%d, %v]", resp.StatusCode, resp.Latency.Round(time.Millisecond))
+}
+
+// responseDetails formats the response headers and body in --print-response mode.
+// Lines are built up front so output of parallel workers does not interleave.
+func (l *listener) responseDetails(resp *forwardResponse) string {
+	if !l.printResponse || resp == nil {
+		return ""
+	}
+
+	var b strings.Builder
+	keys := make([]string, 0, len(resp.Header))
+	for key := range resp.Header {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		for _, value := range resp.Header[key] {
+			fmt.Fprintf(&b, "  %s: %s\n", key, value)
+		}
+	}
+	if resp.Body != "" {
+		b.WriteString("\n")
+		for _, line := range strings.Split(strings.TrimRight(prettyBody(resp.Body), "\n"), "\n") {
+			fmt.Fprintf(&b, "  %s\n", line)
+		}
+	}
+	return b.String()
+}
+
+// deadLetter saves a failed delivery so it can be inspected and redelivered with 'volley dlq'.
+// transform is the --transform command still to run on the event when it is redelivered,
+// empty when the event was already transformed.
+func (l *listener) deadLetter(event *api.Event, target string, deliveryErr error, transform string) {
+	if l.dlq == nil {
+		return
+	}
+
+	l.rulesMu.RLock()
+	opts := l.forward
+	l.rulesMu.RUnlock()
+
+	entry := &dlq.Entry{
+		Event:           *event,
+		TargetURL:       target,
+		PreserveRequest: opts.preserveRequest,
+		Transform:       transform,
+		Error:           deliveryErr.Error(),
+	}
+	entry.AddHeaders, entry.RemoveHeaders, entry.RenameHeaders = opts.headers.specs()
+	if opts.signer != nil {
+		entry.ResignProvider, entry.ResignHeader = opts.signer.Provider, opts.signer.Header
+	}
+	var fwdErr *forwardError
+	if errors.As(deliveryErr, &fwdErr) {
+		entry.StatusCode = fwdErr.StatusCode
 	}
 
+	if err := l.dlq.Add(entry); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to save event %s to the dead-letter queue: %v\n", event.EventID, err)
+		return
+	}
+	fmt.Fprintf(os.Stderr, "  Saved to dead-letter queue as %s\n", entry.ID)
+}
+
+// forwardOptions controls how an event is turned into a request to a local target
+type forwardOptions struct {
+	// Use the original method and append the original sub-path and query string
+	preserveRequest bool
+	// Replaces the signature headers with ones made with a local secret; nil keeps them
+	signer *signature.Signer
+	// --add-header, --remove-header and --rename-header
+	headers headerRules
+	// HTTP clients honouring the TLS options; nil uses defaultClients
+	clients *forwardClients
+}
+
+// requestTarget returns the method and URL an event is forwarded with
+func requestTarget(event *api.Event, targetURL string, opts forwardOptions) (string, string, error) {
+	if !opts.preserveRequest {
+		return "POST", targetURL, nil
+	}
+
+	method := event.Method
+	if method == "" {
+		method = "POST"
+	}
+
+	u, err := url.Parse(targetURL)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid target URL: %w", err)
+	}
+	if event.Path != "" && event.Path != "/" {
+		// The recorded path is still percent-encoded, so it is joined to the escaped
+		// target path and kept as is: %2F and %20 reach the target unchanged. A path
+		// that is not valid escaping is taken literally
+		rawPath := strings.TrimSuffix(u.EscapedPath(), "/") + "/" + strings.TrimPrefix(event.Path, "/")
+		if path, err := url.PathUnescape(rawPath); err == nil {
+			u.Path, u.RawPath = path, rawPath
+		} else {
+			u.Path = strings.TrimSuffix(u.Path, "/") + "/" + strings.TrimPrefix(event.Path, "/")
+			u.RawPath = ""
+		}
+	}
+	if event.QueryString != "" {
+		if u.RawQuery != "" {
+			u.RawQuery += "&" + event.QueryString
+		} else {
+			u.RawQuery = event.QueryString
+		}
+	}
+	return method, u.String(), nil
+}
+
+// forwardHeaders builds the headers an event is delivered with: the original headers
+// rewritten by the header rules, the CLI's own headers and, with --resign, a fresh signature
+func forwardHeaders(event *api.Event, body []byte, opts forwardOptions) http.Header {
+	h := make(http.Header)
+
 	// Forward original headers first (preserves signature headers like Paddle-Signature)
 	if event.Headers != nil {
 		for key, values := range event.Headers {
 			for _, value := range values {
-				req.Header.Add(key, value)
+				h.Add(key, value)
 			}
 		}
 	}
+	// Headers of the original connection do not apply to this one
+	stripHopByHop(h)
+	opts.headers.applyRenames(h)
 
 	// Set/override headers (only if not already set from original headers)
-	if req.Header.Get("Content-Type") == "" {
-		req.Header.Set("Content-Type", "application/json")
+	if h.Get("Content-Type") == "" {
+		h.Set("Content-Type", "application/json")
 	}
-	if req.Header.Get("User-Agent") == "" {
-		req.Header.Set("User-Agent", "Volley-CLI/1.0")
+	if h.Get("User-Agent") == "" {
+		h.Set("User-Agent", "Volley-CLI/1.0")
 	}
-	
+
 	// Add Volley-specific headers for tracking
-	req.Header.Set("X-Volley-Event-ID", event.EventID)
-	req.Header.Set("X-Volley-Source-ID", strconv.FormatUint(event.SourceID, 10))
-	req.Header.Set("X-Volley-Source-Slug", event.SourceSlug)
+	h.Set("X-Volley-Event-ID", event.EventID)
+	h.Set("X-Volley-Source-ID", strconv.FormatUint(event.SourceID, 10))
+	h.Set("X-Volley-Source-Slug", event.SourceSlug)
+
+	// User rules come after the CLI's own headers so they can override them
+	opts.headers.applyOverrides(h)
+
+	// Sign last, over the exact bytes that are sent
+	if opts.signer != nil {
+		opts.signer.Sign(h, body, time.Now())
+	}
+	return h
+}
 
+// maxResponseBody caps how much of a local endpoint's response body is kept
+const maxResponseBody = 64 << 10
+
+// forwardResponse is what a local endpoint answered to a forwarded event
+type forwardResponse struct {
+	StatusCode int
+	Latency    time.Duration
+	Header     http.Header
+	Body       string
+}
+
+// forwardEvent delivers an event to one target, an HTTP URL or a local command. The
+// response is returned whenever the target answered, including alongside a
+// *forwardError for status codes >= 400.
+func forwardEvent(ctx context.Context, event *api.Event, targetURL string, opts forwardOptions) (*forwardResponse, error) {
+	if command, ok := strings.CutPrefix(targetURL, execPersistentTargetPrefix); ok {
+		return persistentProcessFor(command).deliver(ctx, event, opts)
+	}
+	if command, ok := strings.CutPrefix(targetURL, execTargetPrefix); ok {
+		return execEvent(ctx, event, command, opts)
+	}
+
+	clients := opts.clients
+	if clients == nil {
+		clients = defaultClients
+	}
+	client, httpURL, err := clients.forTarget(targetURL)
+	if err != nil {
+		return nil, err
+	}
+
+	// Forward raw body as-is to preserve exact bytes (important for signature verification)
+	// Re-encoding JSON would change the exact bytes and break webhook signatures
+	body := []byte(event.RawBody)
+
+	method, reqURL, err := requestTarget(event, httpURL, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, reqURL, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header = forwardHeaders(event, body, opts)
+
+	start := time.Now()
 	resp, err := client.Do(req)
 	if err != nil {
-		return fmt.Errorf("request failed: %w", err)
+		return nil, fmt.Errorf("request failed: %w", err)
 	}
 	defer resp.Body.Close()
 
+	// Keep the start of the body for debugging; a failed read still leaves the status usable
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, maxResponseBody))
+	result := &forwardResponse{
+		StatusCode: resp.StatusCode,
+		Latency:    time.Since(start),
+		Header:     resp.Header,
+		Body:       string(respBody),
+	}
+
 	if resp.StatusCode >= 400 {
-		return fmt.Errorf("local endpoint returned %d", resp.StatusCode)
+		return result, &forwardError{StatusCode: resp.StatusCode}
 	}
 
-	return nil
+	return result, nil
 }
-
@@ -0,0 +1,304 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/volleyhq/volley-cli/internal/filter"
+	"gopkg.in/yaml.v3"
+)
+
+// How often the --config file is checked for changes
+const configPollInterval = time.Second
+
+// listenSpec is the declarative listen configuration read from --config. Command-line
+// flags take precedence over the file for every setting they give.
+type listenSpec struct {
+	Project    string   `yaml:"project"`
+	AllSources bool     `yaml:"all_sources"`
+	Sources    []string `yaml:"sources"`
+
+	ForwardTo stringList            `yaml:"forward_to"`
+	Routes    map[string]stringList `yaml:"routes"`
+
+	Filters       []string `yaml:"filters"`
+	FilterHeaders []string `yaml:"filter_headers"`
+
+	Headers struct {
+		Add    map[string]string `yaml:"add"`
+		Remove []string          `yaml:"remove"`
+		Rename map[string]string `yaml:"rename"`
+	} `yaml:"headers"`
+
+	Retry struct {
+		Retries *int           `yaml:"retries"`
+		Backoff *time.Duration `yaml:"backoff"`
+		On      []string       `yaml:"on"`
+	} `yaml:"retry"`
+}
+
+// stringList accepts either a single string or a list of strings
+type stringList []string
+
+func (s *stringList) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		*s = stringList{node.Value}
+		return nil
+	}
+	var list []string
+	if err := node.Decode(&list); err != nil {
+		return err
+	}
+	*s = list
+	return nil
+}
+
+// loadListenSpec reads and validates the structure of a listen configuration file
+func loadListenSpec(path string) (*listenSpec, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read listen config: %w", err)
+	}
+
+	spec := &listenSpec{}
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(spec); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("invalid listen config %s: %w", path, err)
+	}
+	return spec, nil
+}
+
+// sameSources reports whether two specs listen to the same sources
+func (s *listenSpec) sameSources(other *listenSpec) bool {
+	return s.Project == other.Project && s.AllSources == other.AllSources && slices.Equal(s.Sources, other.Sources)
+}
+
+// routeSpecs returns the routes in the --route source=url syntax, in a stable order
+func (s *listenSpec) routeSpecs() []string {
+	names := make([]string, 0, len(s.Routes))
+	for name := range s.Routes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var specs []string
+	for _, name := range names {
+		for _, target := range s.Routes[name] {
+			specs = append(specs, name+"="+target)
+		}
+	}
+	return specs
+}
+
+// sortedPairs formats a map as "key<sep>value" entries sorted by key
+func sortedPairs(m map[string]string, sep string) []string {
+	pairs := make([]string, 0, len(m))
+	for k, v := range m {
+		pairs = append(pairs, k+sep+v)
+	}
+	sort.Strings(pairs)
+	return pairs
+}
+
+// listenRules are the settings --config can change while the listener is running
+type listenRules struct {
+	filters []filter.Filter
+	headers headerRules
+	retry   retryPolicy
+	routes  sourceRoutes
+	// Targets of sources without a route: --forward-to URLs and --exec commands
+	targets []string
+}
+
+// buildListenRules combines the flags with spec, which may be nil. A flag that was set
+// on the command line (changed) wins over the file.
+func buildListenRules(spec *listenSpec, changed func(string) bool) (listenRules, error) {
+	if spec == nil {
+		spec = &listenSpec{}
+	}
+	pick := func(flag string, flagValue, specValue []string) []string {
+		if changed(flag) || specValue == nil {
+			return flagValue
+		}
+		return specValue
+	}
+
+	var rules listenRules
+
+	policy := retryPolicy{retries: retries, backoff: retryBackoff}
+	if spec.Retry.Retries != nil && !changed("retries") {
+		policy.retries = *spec.Retry.Retries
+	}
+	if spec.Retry.Backoff != nil && !changed("retry-backoff") {
+		policy.backoff = *spec.Retry.Backoff
+	}
+	if policy.retries < 0 {
+		return rules, fmt.Errorf("--retries must not be negative")
+	}
+	if policy.backoff <= 0 {
+		return rules, fmt.Errorf("--retry-backoff must be positive")
+	}
+	var err error
+	policy.retryOn, err = parseRetryOn(pick("retry-on", retryOn, spec.Retry.On))
+	if err != nil {
+		return rules, err
+	}
+	rules.retry = policy
+
+	for _, expr := range pick("filter-header", headerFilters, spec.FilterHeaders) {
+		f, err := filter.ParseHeader(expr)
+		if err != nil {
+			return rules, err
+		}
+		rules.filters = append(rules.filters, f)
+	}
+	for _, expr := range pick("filter", bodyFilters, spec.Filters) {
+		f, err := filter.Parse(expr)
+		if err != nil {
+			return rules, err
+		}
+		rules.filters = append(rules.filters, f)
+	}
+
+	var add, rename []string
+	if spec.Headers.Add != nil {
+		add = sortedPairs(spec.Headers.Add, ": ")
+	}
+	if spec.Headers.Rename != nil {
+		rename = sortedPairs(spec.Headers.Rename, "=")
+	}
+	rules.headers, err = parseHeaderRules(
+		pick("add-header", addHeaders, add),
+		pick("remove-header", removeHeaders, spec.Headers.Remove),
+		pick("rename-header", renameHeaders, rename),
+	)
+	if err != nil {
+		return rules, err
+	}
+
+	var routes []string
+	if spec.Routes != nil {
+		routes = spec.routeSpecs()
+	}
+	rules.routes, err = parseRoutes(pick("route", routeSpecs, routes))
+	if err != nil {
+		return rules, err
+	}
+
+	rules.targets = append(rules.targets, pick("forward-to", forwardURLs, spec.ForwardTo)...)
+	for _, command := range execCommands {
+		rules.targets = append(rules.targets, execTarget(command, execPersistent))
+	}
+	return rules, nil
+}
+
+// sourceTargets maps every source to its targets. Without capture sinks every source
+// needs at least one target.
+func (r listenRules) sourceTargets(sources []*listenSource, capture bool) (map[uint64][]string, error) {
+	if unused := r.routes.unused(sources); len(unused) > 0 {
+		return nil, fmt.Errorf("--route %s does not match any source", strings.Join(unused, ", "))
+	}
+
+	targets := make(map[uint64][]string, len(sources))
+	for _, src := range sources {
+		targets[src.id] = r.routes.targetsFor(src, r.targets)
+		if len(targets[src.id]) == 0 && !capture {
+			return nil, fmt.Errorf("no target for source '%s': add --forward-to or --route %s=<url>", src.ingestionID, src.slug)
+		}
+	}
+	return targets, nil
+}
+
+// applyRules switches the listener to new rules; deliveries already running finish
+// with the previous ones
+func (l *listener) applyRules(rules listenRules) error {
+	sources := make([]*listenSource, 0, len(l.sources))
+	for _, src := range l.sources {
+		sources = append(sources, src)
+	}
+	targets, err := rules.sourceTargets(sources, len(l.sinks) > 0)
+	if err != nil {
+		return err
+	}
+
+	l.rulesMu.Lock()
+	defer l.rulesMu.Unlock()
+	for _, src := range sources {
+		src.targets = targets[src.id]
+	}
+	l.filters = rules.filters
+	l.forward.headers = rules.headers
+	l.retry = rules.retry
+	return nil
+}
+
+// configReloader applies changes of the --config file to a running listener
+type configReloader struct {
+	l       *listener
+	path    string
+	spec    *listenSpec // the configuration the listener was started with
+	changed func(string) bool
+}
+
+// reload re-reads the file. A file that fails to load or validate leaves the running
+// configuration untouched.
+func (r *configReloader) reload() error {
+	spec, err := loadListenSpec(r.path)
+	if err == nil {
+		var rules listenRules
+		rules, err = buildListenRules(spec, r.changed)
+		if err == nil {
+			err = r.l.applyRules(rules)
+		}
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "✗ Failed to reload %s, keeping the previous configuration: %v\n", r.path, err)
+		return err
+	}
+
+	fmt.Printf("✓ Reloaded %s\n", r.path)
+	if !spec.sameSources(r.spec) {
+		fmt.Fprintln(os.Stderr, "Warning: changes to project and sources take effect after a restart")
+	}
+	return nil
+}
+
+// watch reloads the file whenever it changes until ctx is cancelled
+func (r *configReloader) watch(ctx context.Context) {
+	modTime, size := fileVersion(r.path)
+	ticker := time.NewTicker(configPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		newModTime, newSize := fileVersion(r.path)
+		if newModTime.Equal(modTime) && newSize == size {
+			continue
+		}
+		modTime, size = newModTime, newSize
+		r.reload()
+	}
+}
+
+// fileVersion returns what is compared to notice that a file changed
+func fileVersion(path string) (time.Time, int64) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return time.Time{}, -1
+	}
+	return info.ModTime(), info.Size()
+}
@@ -0,0 +1,241 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Set in the environment of the background process started by 'volley listen --daemon'
+const daemonEnv = "VOLLEY_LISTEN_DAEMON"
+
+// How long 'volley listen --daemon' waits for the background listener to come up
+const daemonStartTimeout = 30 * time.Second
+
+func runningAsDaemon() bool {
+	return os.Getenv(daemonEnv) == "1"
+}
+
+// startDaemon runs the same listen command again as a background process detached from
+// the terminal, with its output going to the daemon log file. It returns once the
+// listener answers on its control socket.
+func startDaemon() error {
+	client := newDaemonClient()
+	if status, err := client.status(); err == nil {
+		return fmt.Errorf("a listener is already running in the background (PID %d); stop it with 'volley daemon stop'", status.PID)
+	}
+
+	if err := os.MkdirAll(daemonDir(), 0700); err != nil {
+		return fmt.Errorf("failed to create daemon directory: %w", err)
+	}
+	logPath := daemonFile(daemonLogFile)
+	logFile, err := os.OpenFile(logPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open daemon log: %w", err)
+	}
+	defer logFile.Close()
+	// Where the output of this run starts, to show it if the listener fails to start
+	logStart, err := logFile.Seek(0, io.SeekEnd)
+	if err != nil {
+		return fmt.Errorf("failed to open daemon log: %w", err)
+	}
+
+	exe, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to find the volley executable: %w", err)
+	}
+	var args []string
+	for _, arg := range os.Args[1:] {
+		if arg != "--daemon" && !strings.HasPrefix(arg, "--daemon=") {
+			args = append(args, arg)
+		}
+	}
+	fmt.Fprintf(logFile, "\n--- %s: volley %s\n", time.Now().Format(time.RFC3339), strings.Join(args, " "))
+
+	cmd := exec.Command(exe, args...)
+	cmd.Env = append(os.Environ(), daemonEnv+"=1")
+	cmd.Stdout = logFile
+	cmd.Stderr = logFile
+	cmd.SysProcAttr = detachedProcAttr()
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to start background listener: %w", err)
+	}
+
+	exited := make(chan struct{})
+	go func() {
+		cmd.Wait()
+		close(exited)
+	}()
+
+	ticker := time.NewTicker(200 * time.Millisecond)
+	defer ticker.Stop()
+	deadline := time.After(daemonStartTimeout)
+	for {
+		select {
+		case <-exited:
+			output, _ := readFrom(logPath, logStart)
+			return fmt.Errorf("the background listener exited during startup:\n%s", strings.TrimSpace(output))
+		case <-deadline:
+			return fmt.Errorf("the background listener did not become ready within %s; see %s", daemonStartTimeout, logPath)
+		case <-ticker.C:
+		}
+
+		status, err := client.status()
+		if err != nil {
+			continue
+		}
+		fmt.Printf("✓ Listening in the background (PID %d)\n", status.PID)
+		fmt.Printf("Logs: %s\n", logPath)
+		fmt.Println("Use 'volley daemon status', 'volley daemon logs' and 'volley daemon stop' to manage it")
+		return nil
+	}
+}
+
+// readFrom returns the contents of a file from offset on
+func readFrom(path string, offset int64) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		return "", err
+	}
+	data, err := io.ReadAll(f)
+	return string(data), err
+}
+
+// daemonStatus is what the control socket reports about a background listener
+type daemonStatus struct {
+	PID       int                  `json:"pid"`
+	StartedAt time.Time            `json:"started_at"`
+	Sources   []daemonSourceStatus `json:"sources"`
+	Config    string               `json:"config,omitempty"`
+	LogFile   string               `json:"log_file"`
+	Paused    bool                 `json:"paused"`
+	InFlight  int64                `json:"in_flight"`
+	LastEvent *daemonEventStatus   `json:"last_event,omitempty"`
+}
+
+type daemonSourceStatus struct {
+	Slug        string   `json:"slug"`
+	IngestionID string   `json:"ingestion_id"`
+	Connection  string   `json:"connection,omitempty"`
+	Targets     []string `json:"targets"`
+}
+
+type daemonEventStatus struct {
+	EventID    string    `json:"event_id"`
+	SourceSlug string    `json:"source_slug"`
+	ReceivedAt time.Time `json:"received_at"`
+}
+
+// controlServer answers 'volley daemon' commands on a Unix socket in the config directory
+type controlServer struct {
+	l         *listener
+	quit      chan<- os.Signal
+	startedAt time.Time
+	server    *http.Server
+}
+
+// startControlServer listens on the control socket and writes the PID file
+func startControlServer(l *listener, quit chan<- os.Signal) (*controlServer, error) {
+	if err := os.MkdirAll(daemonDir(), 0700); err != nil {
+		return nil, fmt.Errorf("failed to create daemon directory: %w", err)
+	}
+	socket := daemonFile(daemonSocketFile)
+	// A socket file left behind by a listener that did not exit cleanly blocks Listen
+	os.Remove(socket)
+	ln, err := net.Listen("unix", socket)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open control socket: %w", err)
+	}
+	if err := os.WriteFile(daemonFile(daemonPIDFile), []byte(strconv.Itoa(os.Getpid())+"\n"), 0600); err != nil {
+		ln.Close()
+		return nil, fmt.Errorf("failed to write PID file: %w", err)
+	}
+
+	c := &controlServer{l: l, quit: quit, startedAt: time.Now()}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /status", c.getStatus)
+	mux.HandleFunc("POST /stop", c.stopListener)
+	mux.HandleFunc("POST /reload", c.reloadConfig)
+	c.server = &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+
+	go func() {
+		if err := c.server.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			fmt.Fprintf(os.Stderr, "Warning: control socket stopped: %v\n", err)
+		}
+	}()
+	return c, nil
+}
+
+// stop closes the control socket and removes the PID file
+func (c *controlServer) stop() {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	c.server.Shutdown(ctx)
+	os.Remove(daemonFile(daemonSocketFile))
+	os.Remove(daemonFile(daemonPIDFile))
+}
+
+func (c *controlServer) getStatus(w http.ResponseWriter, r *http.Request) {
+	status := daemonStatus{
+		PID:       os.Getpid(),
+		StartedAt: c.startedAt,
+		LogFile:   daemonFile(daemonLogFile),
+		Paused:    c.l.dispatcher.paused(),
+		InFlight:  c.l.dispatcher.inFlight(),
+	}
+	if c.l.config != nil {
+		status.Config, _ = filepath.Abs(c.l.config.path)
+	}
+
+	c.l.rulesMu.RLock()
+	for _, src := range c.l.sources {
+		source := daemonSourceStatus{Slug: src.slug, IngestionID: src.ingestionID, Targets: src.targets}
+		if src.connection != nil {
+			source.Connection = fmt.Sprintf("%s (ID: %d)", src.connection.Name, src.connection.ID)
+		}
+		status.Sources = append(status.Sources, source)
+	}
+	c.l.rulesMu.RUnlock()
+	sort.Slice(status.Sources, func(i, j int) bool { return status.Sources[i].Slug < status.Sources[j].Slug })
+
+	if records := c.l.history.list(); len(records) > 0 {
+		last := records[len(records)-1]
+		status.LastEvent = &daemonEventStatus{EventID: last.Event.EventID, SourceSlug: last.Event.SourceSlug, ReceivedAt: last.ReceivedAt}
+	}
+	writeJSON(w, http.StatusOK, status)
+}
+
+// stopListener shuts the listener down like Ctrl+C: polling stops and queued deliveries finish
+func (c *controlServer) stopListener(w http.ResponseWriter, r *http.Request) {
+	select {
+	case c.quit <- os.Interrupt:
+	default:
+	}
+	writeJSON(w, http.StatusAccepted, map[string]string{"status": "stopping"})
+}
+
+func (c *controlServer) reloadConfig(w http.ResponseWriter, r *http.Request) {
+	if c.l.config == nil {
+		writeJSON(w, http.StatusConflict, map[string]string{"error": "the listener was not started with --config"})
+		return
+	}
+	if err := c.l.config.reload(); err != nil {
+		writeJSON(w, http.StatusUnprocessableEntity, map[string]string{"error": err.Error()})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"status": "reloaded"})
+}
@@ -0,0 +1,130 @@
+package cmd
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+
+	"github.com/volleyhq/volley-cli/internal/api"
+)
+
+// dispatchQueueSize bounds how many events may wait for a free worker before
+// the poll loop blocks, so a slow local handler applies backpressure
+const dispatchQueueSize = 100
+
+// dispatcher forwards events on a bounded pool of workers so one slow local handler
+// does not stall the poll loop. In ordered mode every source is pinned to one worker,
+// which keeps events of the same source in the order they were submitted.
+type dispatcher struct {
+	queues  []chan *api.Event
+	ordered bool
+	wg      sync.WaitGroup
+	pending atomic.Int64
+
+	// Closed when forwarding resumes; nil while not paused
+	mu      sync.Mutex
+	resumed chan struct{}
+}
+
+// newDispatcher starts concurrency workers that hand each event to deliver
+func newDispatcher(ctx context.Context, concurrency int, ordered bool, deliver func(context.Context, *api.Event)) *dispatcher {
+	d := &dispatcher{ordered: ordered}
+
+	// Unordered workers share a single queue; ordered ones each get their own
+	queueCount := 1
+	if ordered {
+		queueCount = concurrency
+	}
+	d.queues = make([]chan *api.Event, queueCount)
+	for i := range d.queues {
+		d.queues[i] = make(chan *api.Event, dispatchQueueSize)
+	}
+
+	for i := 0; i < concurrency; i++ {
+		queue := d.queues[i%queueCount]
+		d.wg.Add(1)
+		go func() {
+			defer d.wg.Done()
+			for event := range queue {
+				d.waitResumed(ctx)
+				deliver(ctx, event)
+				d.pending.Add(-1)
+			}
+		}()
+	}
+	return d
+}
+
+// submit queues an event for delivery. It blocks while the queue is full and
+// returns false if ctx is cancelled before the event could be queued.
+func (d *dispatcher) submit(ctx context.Context, event *api.Event) bool {
+	queue := d.queues[0]
+	if d.ordered {
+		queue = d.queues[event.SourceID%uint64(len(d.queues))]
+	}
+
+	d.pending.Add(1)
+	select {
+	case queue <- event:
+		return true
+	case <-ctx.Done():
+		d.pending.Add(-1)
+		return false
+	}
+}
+
+// inFlight returns the number of events queued or being delivered
+func (d *dispatcher) inFlight() int64 {
+	return d.pending.Load()
+}
+
+// pause holds back deliveries that have not started yet until resume is called.
+// Events keep being queued until the queue is full.
+func (d *dispatcher) pause() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.resumed == nil {
+		d.resumed = make(chan struct{})
+	}
+}
+
+// resume lets paused workers continue delivering
+func (d *dispatcher) resume() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.resumed != nil {
+		close(d.resumed)
+		d.resumed = nil
+	}
+}
+
+// paused reports whether deliveries are currently held back
+func (d *dispatcher) paused() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.resumed != nil
+}
+
+// waitResumed blocks while the dispatcher is paused or until ctx is cancelled
+func (d *dispatcher) waitResumed(ctx context.Context) {
+	d.mu.Lock()
+	resumed := d.resumed
+	d.mu.Unlock()
+	if resumed == nil {
+		return
+	}
+	select {
+	case <-resumed:
+	case <-ctx.Done():
+	}
+}
+
+// close stops accepting events and waits until every queued delivery has finished.
+// A paused dispatcher is resumed so the queue can drain.
+func (d *dispatcher) close() {
+	d.resume()
+	for _, queue := range d.queues {
+		close(queue)
+	}
+	d.wg.Wait()
+}
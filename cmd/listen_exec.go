@@ -0,0 +1,268 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/volleyhq/volley-cli/internal/api"
+)
+
+// Targets with these prefixes are local commands instead of URLs
+const (
+	execTargetPrefix           = "exec:"
+	execPersistentTargetPrefix = "exec-persistent:"
+)
+
+// execTimeout bounds how long a command may take to handle one event
+const execTimeout = 30 * time.Second
+
+// execError is returned when a command exits with a non-zero status or replies with an error
+type execError struct {
+	ExitCode int
+	Message  string
+}
+
+func (e *execError) Error() string {
+	if e.ExitCode != 0 {
+		if e.Message != "" {
+			return fmt.Sprintf("command exited with status %d: %s", e.ExitCode, e.Message)
+		}
+		return fmt.Sprintf("command exited with status %d", e.ExitCode)
+	}
+	if e.Message == "" {
+		return "command reported a failure"
+	}
+	return "command failed: " + e.Message
+}
+
+// execTarget returns the target that runs command for every event, or keeps one
+// process running that handles events as newline-delimited JSON
+func execTarget(command string, persistent bool) string {
+	if persistent {
+		return execPersistentTargetPrefix + command
+	}
+	return execTargetPrefix + command
+}
+
+// execEvent delivers an event to a command started for this event alone. The raw body
+// is written to stdin, headers are passed CGI-style as HTTP_* environment variables and
+// event details as VOLLEY_* ones. A non-zero exit status fails the delivery.
+func execEvent(ctx context.Context, event *api.Event, command string, opts forwardOptions) (*forwardResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, execTimeout)
+	defer cancel()
+
+	body := []byte(event.RawBody)
+	headers := forwardHeaders(event, body, opts)
+
+	var stdout, stderr bytes.Buffer
+	cmd := shellCommand(ctx, command)
+	cmd.Stdin = bytes.NewReader(body)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	cmd.Env = append(os.Environ(),
+		"VOLLEY_EVENT_ID="+event.EventID,
+		"VOLLEY_SOURCE_ID="+strconv.FormatUint(event.SourceID, 10),
+		"VOLLEY_SOURCE_SLUG="+event.SourceSlug,
+		"VOLLEY_METHOD="+event.Method,
+		"VOLLEY_PATH="+event.Path,
+		"VOLLEY_QUERY_STRING="+event.QueryString,
+	)
+	for key, values := range headers {
+		name := "HTTP_" + strings.ToUpper(strings.ReplaceAll(key, "-", "_"))
+		cmd.Env = append(cmd.Env, name+"="+strings.Join(values, ", "))
+	}
+
+	start := time.Now()
+	err := cmd.Run()
+	resp := &forwardResponse{Latency: time.Since(start), Body: truncateBody(stdout.String())}
+
+	var exitErr *exec.ExitError
+	switch {
+	case errors.As(err, &exitErr):
+		return resp, &execError{ExitCode: exitErr.ExitCode(), Message: strings.TrimSpace(truncateBody(stderr.String()))}
+	case err != nil:
+		return nil, fmt.Errorf("failed to run command: %w", err)
+	}
+	return resp, nil
+}
+
+// truncateBody caps captured output like response bodies are capped
+func truncateBody(s string) string {
+	if len(s) > maxResponseBody {
+		return s[:maxResponseBody]
+	}
+	return s
+}
+
+// execMessage is one line written to a persistent command
+type execMessage struct {
+	api.Event
+	// Headers after the header rules and re-signing were applied
+	Headers http.Header `json:"headers"`
+}
+
+// execReply is the line a persistent command answers every event with
+type execReply struct {
+	OK    bool   `json:"ok"`
+	Error string `json:"error,omitempty"`
+}
+
+// persistentProcess is a long-lived command that receives one event per line on stdin
+// and answers each with one execReply line on stdout. Events are sent one at a time.
+type persistentProcess struct {
+	command string
+
+	mu     sync.Mutex
+	cmd    *exec.Cmd
+	stdin  io.WriteCloser
+	stdout *bufio.Reader
+}
+
+var (
+	persistentMu        sync.Mutex
+	persistentProcesses = make(map[string]*persistentProcess)
+)
+
+// persistentProcessFor returns the shared process for command
+func persistentProcessFor(command string) *persistentProcess {
+	persistentMu.Lock()
+	defer persistentMu.Unlock()
+
+	p, ok := persistentProcesses[command]
+	if !ok {
+		p = &persistentProcess{command: command}
+		persistentProcesses[command] = p
+	}
+	return p
+}
+
+// stopPersistentProcesses closes the stdin of every persistent command so they can
+// exit, and waits for them
+func stopPersistentProcesses() {
+	persistentMu.Lock()
+	defer persistentMu.Unlock()
+
+	for _, p := range persistentProcesses {
+		p.mu.Lock()
+		p.stop()
+		p.mu.Unlock()
+	}
+}
+
+// start launches the command if it is not running. It is called with mu held.
+func (p *persistentProcess) start() error {
+	if p.cmd != nil {
+		return nil
+	}
+
+	// Not tied to a delivery context: the process outlives single events
+	cmd := shellCommand(context.Background(), p.command)
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	cmd.Stderr = os.Stderr
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to start command: %w", err)
+	}
+
+	p.cmd = cmd
+	p.stdin = stdin
+	p.stdout = bufio.NewReader(stdout)
+	return nil
+}
+
+// stop ends the process; it is restarted on the next event. It is called with mu held.
+func (p *persistentProcess) stop() {
+	if p.cmd == nil {
+		return
+	}
+	p.stdin.Close()
+	done := make(chan struct{})
+	go func() {
+		p.cmd.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		p.cmd.Process.Kill()
+		<-done
+	}
+	p.cmd = nil
+}
+
+// deliver sends one event and waits for its reply. A process that does not answer in
+// time or breaks the protocol is stopped, so the next event starts from a clean state.
+func (p *persistentProcess) deliver(ctx context.Context, event *api.Event, opts forwardOptions) (*forwardResponse, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if err := p.start(); err != nil {
+		return nil, err
+	}
+
+	body := []byte(event.RawBody)
+	line, err := json.Marshal(execMessage{Event: *event, Headers: forwardHeaders(event, body, opts)})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode event: %w", err)
+	}
+
+	start := time.Now()
+	if _, err := p.stdin.Write(append(line, '\n')); err != nil {
+		p.stop()
+		return nil, fmt.Errorf("failed to write to command: %w", err)
+	}
+
+	type result struct {
+		line string
+		err  error
+	}
+	replies := make(chan result, 1)
+	go func() {
+		line, err := p.stdout.ReadString('\n')
+		replies <- result{line, err}
+	}()
+
+	var reply result
+	select {
+	case reply = <-replies:
+	case <-time.After(execTimeout):
+		p.stop()
+		return nil, fmt.Errorf("command did not reply within %v", execTimeout)
+	case <-ctx.Done():
+		p.stop()
+		return nil, ctx.Err()
+	}
+	if reply.err != nil {
+		p.stop()
+		return nil, fmt.Errorf("command exited before replying: %w", reply.err)
+	}
+
+	resp := &forwardResponse{Latency: time.Since(start), Body: strings.TrimSpace(reply.line)}
+	var r execReply
+	if err := json.Unmarshal([]byte(reply.line), &r); err != nil {
+		p.stop()
+		return resp, fmt.Errorf("command replied with invalid JSON: %w", err)
+	}
+	if !r.OK {
+		return resp, &execError{Message: r.Error}
+	}
+	return resp, nil
+}
@@ -0,0 +1,110 @@
+package cmd
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// hopByHopHeaders describe a single connection and are never forwarded, together with
+// Host and Content-Length, which are set for the request to the local target
+var hopByHopHeaders = []string{
+	"Connection",
+	"Keep-Alive",
+	"Proxy-Authenticate",
+	"Proxy-Authorization",
+	"Proxy-Connection",
+	"Te",
+	"Trailer",
+	"Transfer-Encoding",
+	"Upgrade",
+	"Host",
+	"Content-Length",
+}
+
+// headerRules rewrite the headers of forwarded requests
+type headerRules struct {
+	add    [][2]string // name, value; set in order, replacing existing values
+	remove []string
+	rename [][2]string // old name, new name
+}
+
+// parseHeaderRules validates --add-header (Name: value), --remove-header (Name) and
+// --rename-header (Old=New) values
+func parseHeaderRules(add, remove, rename []string) (headerRules, error) {
+	var rules headerRules
+	for _, v := range add {
+		name, value, ok := strings.Cut(v, ":")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" {
+			return rules, fmt.Errorf("invalid --add-header %q: expected 'Name: value'", v)
+		}
+		rules.add = append(rules.add, [2]string{name, strings.TrimSpace(value)})
+	}
+	for _, v := range remove {
+		name := strings.TrimSpace(v)
+		if name == "" {
+			return rules, fmt.Errorf("invalid --remove-header %q: expected a header name", v)
+		}
+		rules.remove = append(rules.remove, name)
+	}
+	for _, v := range rename {
+		from, to, ok := strings.Cut(v, "=")
+		from, to = strings.TrimSpace(from), strings.TrimSpace(to)
+		if !ok || from == "" || to == "" {
+			return rules, fmt.Errorf("invalid --rename-header %q: expected 'Old=New'", v)
+		}
+		rules.rename = append(rules.rename, [2]string{from, to})
+	}
+	return rules, nil
+}
+
+// specs formats the rules back into --add-header, --remove-header and --rename-header
+// values, so they can be stored with a dead-lettered event
+func (r headerRules) specs() (add, remove, rename []string) {
+	for _, a := range r.add {
+		add = append(add, a[0]+": "+a[1])
+	}
+	remove = append(remove, r.remove...)
+	for _, rn := range r.rename {
+		rename = append(rename, rn[0]+"="+rn[1])
+	}
+	return add, remove, rename
+}
+
+// stripHopByHop removes hop-by-hop headers, including any the Connection header names
+func stripHopByHop(h http.Header) {
+	for _, value := range h.Values("Connection") {
+		for _, name := range strings.Split(value, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				h.Del(name)
+			}
+		}
+	}
+	for _, name := range hopByHopHeaders {
+		h.Del(name)
+	}
+}
+
+// applyRenames moves the values of renamed headers to their new name
+func (r headerRules) applyRenames(h http.Header) {
+	for _, rename := range r.rename {
+		values := h.Values(rename[0])
+		if len(values) == 0 {
+			continue
+		}
+		h.Del(rename[0])
+		h[http.CanonicalHeaderKey(rename[1])] = append([]string(nil), values...)
+	}
+}
+
+// applyOverrides removes and then adds headers; it runs after the CLI's own headers are
+// set so those can be removed or overridden as well
+func (r headerRules) applyOverrides(h http.Header) {
+	for _, name := range r.remove {
+		h.Del(name)
+	}
+	for _, add := range r.add {
+		h.Set(add[0], add[1])
+	}
+}
@@ -0,0 +1,124 @@
+package cmd
+
+import (
+	"sync"
+	"time"
+
+	"github.com/volleyhq/volley-cli/internal/api"
+)
+
+// historySize is the number of recent events kept in memory with their deliveries
+const historySize = 500
+
+// deliveryRecord is the outcome of forwarding an event to one target
+type deliveryRecord struct {
+	Target      string              `json:"target"`
+	StatusCode  int                 `json:"status_code,omitempty"`
+	LatencyMs   int64               `json:"latency_ms"`
+	Headers     map[string][]string `json:"headers,omitempty"`
+	Body        string              `json:"body,omitempty"`
+	Error       string              `json:"error,omitempty"`
+	DeliveredAt time.Time           `json:"delivered_at"`
+}
+
+// newDeliveryRecord builds the record of one delivery from its response and error
+func newDeliveryRecord(target string, resp *forwardResponse, err error) deliveryRecord {
+	record := deliveryRecord{Target: target, DeliveredAt: time.Now()}
+	if resp != nil {
+		record.StatusCode = resp.StatusCode
+		record.LatencyMs = resp.Latency.Milliseconds()
+		record.Headers = resp.Header
+		record.Body = resp.Body
+	}
+	if err != nil {
+		record.Error = err.Error()
+	}
+	return record
+}
+
+// eventRecord is an event seen by the listener together with every delivery attempt made for it
+type eventRecord struct {
+	Event      api.Event        `json:"event"`
+	ReceivedAt time.Time        `json:"received_at"`
+	Deliveries []deliveryRecord `json:"deliveries"`
+	// Outcome of --verify: "valid" or the reason the signature was rejected
+	Signature string `json:"signature,omitempty"`
+}
+
+// history keeps the most recent events and their local responses, oldest first
+type history struct {
+	mu      sync.Mutex
+	records []*eventRecord
+	byID    map[string]*eventRecord
+}
+
+func newHistory() *history {
+	return &history{byID: make(map[string]*eventRecord)}
+}
+
+// add starts tracking an event, dropping the oldest one once the history is full
+func (h *history) add(event *api.Event) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if _, ok := h.byID[event.EventID]; ok {
+		return
+	}
+	if len(h.records) >= historySize {
+		delete(h.byID, h.records[0].Event.EventID)
+		h.records = h.records[1:]
+	}
+	record := &eventRecord{Event: *event, ReceivedAt: time.Now()}
+	h.records = append(h.records, record)
+	h.byID[event.EventID] = record
+}
+
+// recordDelivery stores the outcome of one delivery of an event
+func (h *history) recordDelivery(eventID string, delivery deliveryRecord) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if record, ok := h.byID[eventID]; ok {
+		record.Deliveries = append(record.Deliveries, delivery)
+	}
+}
+
+// setSignature stores the outcome of verifying an event's signature
+func (h *history) setSignature(eventID, outcome string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if record, ok := h.byID[eventID]; ok {
+		record.Signature = outcome
+	}
+}
+
+// get returns a copy of the record for an event
+func (h *history) get(eventID string) (eventRecord, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	record, ok := h.byID[eventID]
+	if !ok {
+		return eventRecord{}, false
+	}
+	return copyRecord(record), true
+}
+
+// list returns copies of all records, oldest first
+func (h *history) list() []eventRecord {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	records := make([]eventRecord, len(h.records))
+	for i, record := range h.records {
+		records[i] = copyRecord(record)
+	}
+	return records
+}
+
+func copyRecord(record *eventRecord) eventRecord {
+	c := *record
+	c.Deliveries = append([]deliveryRecord(nil), record.Deliveries...)
+	return c
+}
@@ -0,0 +1,146 @@
+package cmd
+
+import (
+	"context"
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+//go:embed inspect
+var inspectAssets embed.FS
+
+// inspector serves the local web inspector of 'volley listen --inspect': a small UI and
+// JSON API over the listener's history of events and local responses
+type inspector struct {
+	l      *listener
+	ctx    context.Context // used for replayed events
+	server *http.Server
+}
+
+// startInspector listens on addr and serves the inspector until stop is called. An
+// address without a host, such as :4040, is bound to the loopback interface only.
+func startInspector(l *listener, ctx context.Context, addr string) (*inspector, error) {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --inspect address %q: %w", addr, err)
+	}
+	if host == "" {
+		host = "127.0.0.1"
+	}
+	// Requests are only accepted for the bound host, which a wildcard address does not name
+	if ip := net.ParseIP(host); ip != nil && ip.IsUnspecified() {
+		return nil, fmt.Errorf("invalid --inspect address %q: listening on every interface is not supported, use 127.0.0.1%s or the address of one interface", addr, net.JoinHostPort("", port))
+	}
+	ln, err := net.Listen("tcp", net.JoinHostPort(host, port))
+	if err != nil {
+		return nil, fmt.Errorf("failed to start inspector: %w", err)
+	}
+
+	assets, err := fs.Sub(inspectAssets, "inspect")
+	if err != nil {
+		return nil, err
+	}
+
+	i := &inspector{l: l, ctx: ctx}
+	mux := http.NewServeMux()
+	mux.Handle("GET /", http.FileServerFS(assets))
+	mux.HandleFunc("GET /api/events", i.listEvents)
+	mux.HandleFunc("GET /api/events/{id}", i.getEvent)
+	mux.HandleFunc("POST /api/events/{id}/replay", i.replayEvent)
+	i.server = &http.Server{Handler: localOnly(mux, host), ReadHeaderTimeout: 10 * time.Second}
+
+	go func() {
+		if err := i.server.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			fmt.Fprintf(os.Stderr, "Warning: inspector stopped: %v\n", err)
+		}
+	}()
+
+	host, port, _ = net.SplitHostPort(ln.Addr().String())
+	if ip := net.ParseIP(host); ip != nil && ip.IsLoopback() {
+		host = "localhost"
+	}
+	fmt.Printf("Inspector: http://%s\n", net.JoinHostPort(host, port))
+	return i, nil
+}
+
+// localOnly rejects requests whose Host or Origin header names anything but this machine
+// or the host the inspector was bound to, so other web pages cannot read or replay events
+// through the browser (DNS rebinding, cross-site requests)
+func localOnly(next http.Handler, bindHost string) http.Handler {
+	allowed := func(host string) bool {
+		host = strings.TrimSuffix(strings.ToLower(host), ".")
+		if host == "localhost" || strings.HasSuffix(host, ".localhost") || host == strings.ToLower(bindHost) {
+			return true
+		}
+		ip := net.ParseIP(strings.Trim(host, "[]"))
+		return ip != nil && ip.IsLoopback()
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host := r.Host
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		}
+		if !allowed(host) {
+			writeJSON(w, http.StatusForbidden, map[string]string{"error": "host not allowed"})
+			return
+		}
+		if origin := r.Header.Get("Origin"); origin != "" {
+			u, err := url.Parse(origin)
+			if err != nil || !allowed(u.Hostname()) {
+				writeJSON(w, http.StatusForbidden, map[string]string{"error": "origin not allowed"})
+				return
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// stop shuts the inspector down, giving open requests a moment to finish
+func (i *inspector) stop() {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	i.server.Shutdown(ctx)
+}
+
+// listEvents returns every recorded event, newest first
+func (i *inspector) listEvents(w http.ResponseWriter, r *http.Request) {
+	records := i.l.history.list()
+	for a, b := 0, len(records)-1; a < b; a, b = a+1, b-1 {
+		records[a], records[b] = records[b], records[a]
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"events": records})
+}
+
+func (i *inspector) getEvent(w http.ResponseWriter, r *http.Request) {
+	record, ok := i.l.history.get(r.PathValue("id"))
+	if !ok {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "event not found"})
+		return
+	}
+	writeJSON(w, http.StatusOK, record)
+}
+
+// replayEvent forwards an event to every target again; the outcome shows up in its deliveries
+func (i *inspector) replayEvent(w http.ResponseWriter, r *http.Request) {
+	if !i.l.replay(i.ctx, r.PathValue("id")) {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "event not found"})
+		return
+	}
+	writeJSON(w, http.StatusAccepted, map[string]string{"status": "replaying"})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
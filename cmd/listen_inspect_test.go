@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestStartInspectorRejectsWildcardAddresses(t *testing.T) {
+	for _, addr := range []string{"0.0.0.0:0", "[::]:0", "localhost"} {
+		if i, err := startInspector(nil, context.Background(), addr); err == nil {
+			i.stop()
+			t.Errorf("startInspector(%q) succeeded, want an error", addr)
+		}
+	}
+}
+
+func TestLocalOnly(t *testing.T) {
+	handler := localOnly(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}), "192.168.1.20")
+	tests := []struct {
+		host   string
+		origin string
+		want   int
+	}{
+		{host: "localhost:4040", want: http.StatusOK},
+		{host: "127.0.0.1:4040", want: http.StatusOK},
+		{host: "[::1]:4040", want: http.StatusOK},
+		{host: "app.localhost:4040", want: http.StatusOK},
+		{host: "192.168.1.20:4040", want: http.StatusOK},
+		{host: "localhost:4040", origin: "http://localhost:4040", want: http.StatusOK},
+		{host: "192.168.1.21:4040", want: http.StatusForbidden},
+		{host: "evil.example:4040", want: http.StatusForbidden},
+		{host: "localhost:4040", origin: "https://evil.example", want: http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest("GET", "/api/events", nil)
+		req.Host = tt.host
+		if tt.origin != "" {
+			req.Header.Set("Origin", tt.origin)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		if rec.Code != tt.want {
+			t.Errorf("Host %s, Origin %q: status = %d, want %d", tt.host, tt.origin, rec.Code, tt.want)
+		}
+	}
+}
@@ -0,0 +1,176 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/volleyhq/volley-cli/internal/metrics"
+)
+
+// How long polls of a source may keep failing before /healthz reports the listener unhealthy
+const healthGracePeriod = time.Minute
+
+// Upper bounds in seconds of the forward latency histogram
+var latencyBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// listenMetrics counts what the listener does for --metrics-addr and tracks whether the
+// Volley API is reachable for every source
+type listenMetrics struct {
+	registry *metrics.Registry
+
+	polls          *metrics.Counter
+	pollErrors     *metrics.Counter
+	pollInterval   *metrics.Gauge
+	received       *metrics.Counter
+	forwarded      *metrics.Counter
+	failed         *metrics.Counter
+	skipped        *metrics.Counter
+	forwardLatency *metrics.Histogram
+
+	mu     sync.Mutex
+	health map[string]*sourceHealth // by source slug
+}
+
+// sourceHealth is the outcome of the latest poll of a source
+type sourceHealth struct {
+	polled       bool      // at least one poll finished or the stream connected
+	failingSince time.Time // zero while the latest poll succeeded
+	lastError    string
+}
+
+func newListenMetrics() *listenMetrics {
+	r := metrics.NewRegistry()
+	return &listenMetrics{
+		registry: r,
+
+		polls:          r.Counter("volley_listen_polls_total", "Polls of the Volley API.", "source"),
+		pollErrors:     r.Counter("volley_listen_poll_errors_total", "Polls of the Volley API that failed.", "source"),
+		pollInterval:   r.Gauge("volley_listen_poll_interval_seconds", "Current interval between polls.", "source"),
+		received:       r.Counter("volley_listen_events_received_total", "New events received from Volley.", "source"),
+		forwarded:      r.Counter("volley_listen_events_forwarded_total", "Events delivered to a target.", "source", "target"),
+		failed:         r.Counter("volley_listen_events_failed_total", "Events that could not be delivered to a target.", "source", "target"),
+		skipped:        r.Counter("volley_listen_events_skipped_total", "Events that were not forwarded, by reason: filter, signature or transform.", "source", "reason"),
+		forwardLatency: r.Histogram("volley_listen_forward_duration_seconds", "Latency of the last attempt to deliver an event to a target.", latencyBuckets, "source", "target"),
+
+		health: make(map[string]*sourceHealth),
+	}
+}
+
+// pollDone records the outcome of a poll of a source
+func (m *listenMetrics) pollDone(source string, err error) {
+	m.polls.Inc(source)
+	if err != nil {
+		m.pollErrors.Inc(source)
+	}
+	m.setHealth(source, err)
+}
+
+// setHealth records whether the API could be reached for a source; a connected event
+// stream counts as a successful poll
+func (m *listenMetrics) setHealth(source string, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	h, ok := m.health[source]
+	if !ok {
+		h = &sourceHealth{}
+		m.health[source] = h
+	}
+	h.polled = true
+	if err == nil {
+		h.failingSince = time.Time{}
+		h.lastError = ""
+		return
+	}
+	if h.failingSince.IsZero() {
+		h.failingSince = time.Now()
+	}
+	h.lastError = err.Error()
+}
+
+// check reports the problems of every source. Ready requires the latest poll of every
+// source to have succeeded; healthy only requires that none kept failing longer than
+// healthGracePeriod.
+func (m *listenMetrics) check(sources []string, ready bool) []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var problems []string
+	for _, source := range sources {
+		h, ok := m.health[source]
+		switch {
+		case !ok || !h.polled:
+			if ready {
+				problems = append(problems, fmt.Sprintf("%s: not polled yet", source))
+			}
+		case h.failingSince.IsZero():
+		case ready || time.Since(h.failingSince) > healthGracePeriod:
+			problems = append(problems, fmt.Sprintf("%s: polls failing since %s: %s", source, h.failingSince.Format(time.RFC3339), h.lastError))
+		}
+	}
+	sort.Strings(problems)
+	return problems
+}
+
+// metricsServer serves /metrics, /healthz and /readyz for --metrics-addr
+type metricsServer struct {
+	l      *listener
+	server *http.Server
+}
+
+// startMetricsServer listens on addr and serves the metrics until stop is called
+func startMetricsServer(l *listener, addr string) (*metricsServer, error) {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start metrics server: %w", err)
+	}
+
+	m := &metricsServer{l: l}
+	mux := http.NewServeMux()
+	mux.Handle("GET /metrics", l.metrics.registry.Handler())
+	mux.HandleFunc("GET /healthz", m.healthz)
+	mux.HandleFunc("GET /readyz", m.readyz)
+	m.server = &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+
+	go func() {
+		if err := m.server.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			fmt.Fprintf(os.Stderr, "Warning: metrics server stopped: %v\n", err)
+		}
+	}()
+
+	host, port, _ := net.SplitHostPort(ln.Addr().String())
+	if ip := net.ParseIP(host); host == "" || (ip != nil && ip.IsUnspecified()) {
+		host = "localhost"
+	}
+	fmt.Printf("Metrics: http://%s/metrics\n", net.JoinHostPort(host, port))
+	return m, nil
+}
+
+// stop shuts the metrics server down, giving open requests a moment to finish
+func (m *metricsServer) stop() {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	m.server.Shutdown(ctx)
+}
+
+func (m *metricsServer) healthz(w http.ResponseWriter, r *http.Request) {
+	m.writeCheck(w, m.l.metrics.check(m.l.sourceSlugs(), false))
+}
+
+func (m *metricsServer) readyz(w http.ResponseWriter, r *http.Request) {
+	m.writeCheck(w, m.l.metrics.check(m.l.sourceSlugs(), true))
+}
+
+func (m *metricsServer) writeCheck(w http.ResponseWriter, problems []string) {
+	if len(problems) > 0 {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]interface{}{"status": "unavailable", "problems": problems})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
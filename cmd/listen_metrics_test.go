@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestListenMetricsCheck(t *testing.T) {
+	m := newListenMetrics()
+	sources := []string{"ok", "new", "flaky", "down"}
+
+	m.pollDone("ok", nil)
+	m.pollDone("flaky", errors.New("timeout"))
+	m.pollDone("down", errors.New("unauthorized"))
+	// Failing for longer than the grace period
+	downSince := time.Now().Add(-2 * healthGracePeriod)
+	m.health["down"].failingSince = downSince
+	flakySince := m.health["flaky"].failingSince
+
+	tests := []struct {
+		name  string
+		ready bool
+		want  []string
+	}{
+		{
+			name:  "healthz",
+			ready: false,
+			want:  []string{"down: polls failing since " + downSince.Format(time.RFC3339) + ": unauthorized"},
+		},
+		{
+			name:  "readyz",
+			ready: true,
+			want: []string{
+				"down: polls failing since " + downSince.Format(time.RFC3339) + ": unauthorized",
+				"flaky: polls failing since " + flakySince.Format(time.RFC3339) + ": timeout",
+				"new: not polled yet",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := m.check(sources, tt.ready); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("check() =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(tt.want, "\n"))
+			}
+		})
+	}
+}
+
+func TestListenMetricsCheckRecovers(t *testing.T) {
+	m := newListenMetrics()
+	m.pollDone("src", errors.New("timeout"))
+	m.health["src"].failingSince = time.Now().Add(-2 * healthGracePeriod)
+	if got := m.check([]string{"src"}, false); len(got) != 1 {
+		t.Fatalf("check() = %v, want one problem", got)
+	}
+
+	// A connected stream counts as a successful poll
+	m.setHealth("src", nil)
+	for _, ready := range []bool{false, true} {
+		if got := m.check([]string{"src"}, ready); len(got) != 0 {
+			t.Errorf("check(ready=%v) after recovering = %v, want none", ready, got)
+		}
+	}
+
+	// A new failure starts a new grace period
+	m.pollDone("src", errors.New("timeout"))
+	if got := m.check([]string{"src"}, false); len(got) != 0 {
+		t.Errorf("check() right after a new failure = %v, want none", got)
+	}
+}
@@ -0,0 +1,123 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/volleyhq/volley-cli/internal/api"
+)
+
+// retryMaxBackoff caps the delay between two delivery attempts
+const retryMaxBackoff = 30 * time.Second
+
+// forwardError is returned by forwardEvent when the local endpoint answered with an error status
+type forwardError struct {
+	StatusCode int
+}
+
+func (e *forwardError) Error() string {
+	return fmt.Sprintf("local endpoint returned %d", e.StatusCode)
+}
+
+// retryPolicy decides whether and when a failed forward is attempted again
+type retryPolicy struct {
+	retries int
+	backoff time.Duration
+	// Status code patterns that are retried, e.g. "5xx" or "429".
+	// Refused and reset connections and timeouts are always retried.
+	retryOn []string
+}
+
+// parseRetryOn validates a list of status code patterns such as "5xx", "429" or "502"
+func parseRetryOn(values []string) ([]string, error) {
+	patterns := make([]string, 0, len(values))
+	for _, v := range values {
+		p := strings.ToLower(strings.TrimSpace(v))
+		if p == "" {
+			continue
+		}
+		if len(p) != 3 {
+			return nil, fmt.Errorf("invalid --retry-on value %q: use a status code like 429 or a class like 5xx", v)
+		}
+		if strings.HasSuffix(p, "xx") {
+			if p[0] < '1' || p[0] > '5' {
+				return nil, fmt.Errorf("invalid --retry-on value %q: use a status code like 429 or a class like 5xx", v)
+			}
+		} else if code, err := strconv.Atoi(p); err != nil || code < 100 || code > 599 {
+			return nil, fmt.Errorf("invalid --retry-on value %q: use a status code like 429 or a class like 5xx", v)
+		}
+		patterns = append(patterns, p)
+	}
+	return patterns, nil
+}
+
+// shouldRetry reports whether a failed attempt is worth repeating. Failed --exec
+// commands and errors that persist until the configuration changes, such as a missing
+// unix socket or an untrusted certificate, are not.
+func (p retryPolicy) shouldRetry(err error) bool {
+	var fwdErr *forwardError
+	if !errors.As(err, &fwdErr) {
+		return isTransientNetError(err)
+	}
+
+	code := strconv.Itoa(fwdErr.StatusCode)
+	for _, pattern := range p.retryOn {
+		if pattern == code || (strings.HasSuffix(pattern, "xx") && pattern[0] == code[0]) {
+			return true
+		}
+	}
+	return false
+}
+
+// isTransientNetError reports whether err means the local server is down or
+// restarting: the connection was refused, reset or closed early, or timed out
+func isTransientNetError(err error) bool {
+	if errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// delay returns the wait before retry number attempt (starting at 1): exponential
+// backoff from the base delay with jitter, so a recovering server is not hit in lockstep
+func (p retryPolicy) delay(attempt int) time.Duration {
+	// Doubling stops at the cap, so large attempt numbers cannot overflow
+	d := p.backoff
+	for i := 1; i < attempt && d > 0 && d < retryMaxBackoff; i++ {
+		d *= 2
+	}
+	if d <= 0 || d > retryMaxBackoff {
+		d = retryMaxBackoff
+	}
+	// Keep at least half of the delay and randomize the rest
+	half := d / 2
+	return half + time.Duration(rand.Int64N(int64(half)+1))
+}
+
+// forwardWithRetry forwards an event to one target, retrying according to the policy.
+// It gives up early when ctx is cancelled and returns the response of the last attempt.
+func forwardWithRetry(ctx context.Context, event *api.Event, targetURL string, opts forwardOptions, policy retryPolicy) (*forwardResponse, error) {
+	resp, err := forwardEvent(ctx, event, targetURL, opts)
+	for attempt := 1; err != nil && attempt <= policy.retries && policy.shouldRetry(err); attempt++ {
+		wait := policy.delay(attempt)
+		fmt.Fprintf(os.Stderr, "↻ Retrying event %s -> %s in %v (attempt %d/%d): %v\n", event.EventID, targetURL, wait.Round(time.Millisecond), attempt, policy.retries, err)
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			return resp, err
+		}
+		resp, err = forwardEvent(ctx, event, targetURL, opts)
+	}
+	return resp, err
+}
@@ -0,0 +1,120 @@
+package cmd
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"os"
+	"reflect"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestParseRetryOn(t *testing.T) {
+	tests := []struct {
+		values  []string
+		want    []string
+		wantErr bool
+	}{
+		{values: []string{"5xx", "429"}, want: []string{"5xx", "429"}},
+		{values: []string{" 5XX ", "", "502"}, want: []string{"5xx", "502"}},
+		{values: nil, want: []string{}},
+		{values: []string{"1xx", "599", "100"}, want: []string{"1xx", "599", "100"}},
+		{values: []string{"6xx"}, wantErr: true},
+		{values: []string{"0xx"}, wantErr: true},
+		{values: []string{"600"}, wantErr: true},
+		{values: []string{"099"}, wantErr: true},
+		{values: []string{"50"}, wantErr: true},
+		{values: []string{"5000"}, wantErr: true},
+		{values: []string{"abc"}, wantErr: true},
+		{values: []string{"x5x"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprint(tt.values), func(t *testing.T) {
+			got, err := parseRetryOn(tt.values)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("parseRetryOn() = %v, want an error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseRetryOn() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseRetryOn() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// timeoutError is a net.Error that timed out
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+func dialError(err error) error {
+	return &url.Error{Op: "Post", URL: "http://localhost:3000", Err: &net.OpError{Op: "dial", Net: "tcp", Err: os.NewSyscallError("connect", err)}}
+}
+
+func TestShouldRetry(t *testing.T) {
+	p := retryPolicy{retryOn: []string{"5xx", "429"}}
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "500", err: &forwardError{StatusCode: 500}, want: true},
+		{name: "503 wrapped", err: fmt.Errorf("delivery failed: %w", &forwardError{StatusCode: 503}), want: true},
+		{name: "429", err: &forwardError{StatusCode: 429}, want: true},
+		{name: "404", err: &forwardError{StatusCode: 404}, want: false},
+		{name: "428", err: &forwardError{StatusCode: 428}, want: false},
+		{name: "connection refused", err: dialError(syscall.ECONNREFUSED), want: true},
+		{name: "connection reset", err: &url.Error{Op: "Post", URL: "http://localhost:3000", Err: os.NewSyscallError("read", syscall.ECONNRESET)}, want: true},
+		{name: "closed early", err: &url.Error{Op: "Post", URL: "http://localhost:3000", Err: io.EOF}, want: true},
+		{name: "timeout", err: &url.Error{Op: "Post", URL: "http://localhost:3000", Err: timeoutError{}}, want: true},
+		{name: "missing unix socket", err: dialError(syscall.ENOENT), want: false},
+		{name: "untrusted certificate", err: &url.Error{Op: "Post", URL: "https://localhost:3000", Err: x509.UnknownAuthorityError{}}, want: false},
+		{name: "cancelled", err: &url.Error{Op: "Post", URL: "http://localhost:3000", Err: context.Canceled}, want: false},
+		{name: "exec exit status", err: &execError{ExitCode: 1}, want: false},
+		{name: "exec reply", err: &execError{Message: "bad payload"}, want: false},
+		{name: "other", err: errors.New("invalid target URL"), want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := p.shouldRetry(tt.err); got != tt.want {
+				t.Errorf("shouldRetry(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDelay(t *testing.T) {
+	p := retryPolicy{backoff: time.Second}
+	tests := []struct {
+		attempt  int
+		min, max time.Duration
+	}{
+		{attempt: 1, min: 500 * time.Millisecond, max: time.Second},
+		{attempt: 2, min: time.Second, max: 2 * time.Second},
+		{attempt: 5, min: 8 * time.Second, max: 16 * time.Second},
+		// 32s and beyond are capped, however many attempts were made
+		{attempt: 6, min: retryMaxBackoff / 2, max: retryMaxBackoff},
+		{attempt: 40, min: retryMaxBackoff / 2, max: retryMaxBackoff},
+		{attempt: 100, min: retryMaxBackoff / 2, max: retryMaxBackoff},
+	}
+	for _, tt := range tests {
+		for i := 0; i < 50; i++ {
+			if got := p.delay(tt.attempt); got < tt.min || got > tt.max {
+				t.Fatalf("delay(%d) = %v, want between %v and %v", tt.attempt, got, tt.min, tt.max)
+			}
+		}
+	}
+}
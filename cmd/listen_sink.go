@@ -0,0 +1,98 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/volleyhq/volley-cli/internal/api"
+)
+
+// eventSink records received events for offline debugging and fixture capture
+type eventSink interface {
+	write(event *api.Event) error
+}
+
+// unsafeFileChars are replaced in event IDs used as file names
+var unsafeFileChars = regexp.MustCompile(`[^A-Za-z0-9._-]`)
+
+// dirSink writes every event to a directory: the raw body in one file and the headers
+// and request details in a JSON sidecar next to it
+type dirSink struct {
+	dir string
+}
+
+// eventMeta is the sidecar written next to a captured body
+type eventMeta struct {
+	EventID     string              `json:"event_id"`
+	SourceID    uint64              `json:"source_id"`
+	SourceSlug  string              `json:"source_slug"`
+	CreatedAt   string              `json:"created_at"`
+	Method      string              `json:"method,omitempty"`
+	Path        string              `json:"path,omitempty"`
+	QueryString string              `json:"query_string,omitempty"`
+	Headers     map[string][]string `json:"headers"`
+}
+
+func newDirSink(dir string) (*dirSink, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create output directory: %w", err)
+	}
+	return &dirSink{dir: dir}, nil
+}
+
+// write stores <created>-<event id>.json (or .body when the body is not JSON) and
+// <created>-<event id>.headers.json, so files sort in the order events were created
+func (s *dirSink) write(event *api.Event) error {
+	name := event.CreatedAt.UTC().Format("20060102T150405.000Z") + "-" + unsafeFileChars.ReplaceAllString(event.EventID, "_")
+
+	ext := ".body"
+	if json.Valid([]byte(event.RawBody)) {
+		ext = ".json"
+	}
+	if err := os.WriteFile(filepath.Join(s.dir, name+ext), []byte(event.RawBody), 0644); err != nil {
+		return fmt.Errorf("failed to write body: %w", err)
+	}
+
+	meta, err := json.MarshalIndent(eventMeta{
+		EventID:     event.EventID,
+		SourceID:    event.SourceID,
+		SourceSlug:  event.SourceSlug,
+		CreatedAt:   event.CreatedAt.Format(time.RFC3339Nano),
+		Method:      event.Method,
+		Path:        event.Path,
+		QueryString: event.QueryString,
+		Headers:     event.Headers,
+	}, "", "  ")
+	if err != nil {
+		return err
+	}
+	// Headers carry signatures and often credentials, so only the owner may read them
+	if err := os.WriteFile(filepath.Join(s.dir, name+".headers.json"), append(meta, '\n'), 0600); err != nil {
+		return fmt.Errorf("failed to write headers: %w", err)
+	}
+	return nil
+}
+
+// jsonlSink writes every event as one JSON line, e.g. to stdout for piping into jq
+type jsonlSink struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func (s *jsonlSink) write(event *api.Event) error {
+	line, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err = s.w.Write(append(line, '\n'))
+	return err
+}
@@ -0,0 +1,40 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/volleyhq/volley-cli/internal/api"
+)
+
+func TestDirSinkWrite(t *testing.T) {
+	dir := t.TempDir()
+	s, err := newDirSink(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	event := &api.Event{
+		EventID:   "evt/1",
+		CreatedAt: time.Date(2026, 10, 17, 10, 20, 30, 123e6, time.UTC),
+		Headers:   map[string][]string{"Authorization": {"Bearer secret"}},
+		RawBody:   `{"type":"invoice.paid"}`,
+	}
+	if err := s.write(event); err != nil {
+		t.Fatalf("write() error = %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, "20261017T102030.123Z-evt_1.json")); err != nil {
+		t.Errorf("body was not written: %v", err)
+	}
+	info, err := os.Stat(filepath.Join(dir, "20261017T102030.123Z-evt_1.headers.json"))
+	if err != nil {
+		t.Fatalf("headers were not written: %v", err)
+	}
+	// Headers may hold credentials
+	if runtime.GOOS != "windows" && info.Mode().Perm() != 0600 {
+		t.Errorf("headers file mode = %v, want %v", info.Mode().Perm(), os.FileMode(0600))
+	}
+}
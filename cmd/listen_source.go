@@ -0,0 +1,285 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/volleyhq/volley-cli/internal/api"
+	"github.com/volleyhq/volley-cli/internal/config"
+	"golang.org/x/term"
+)
+
+// listenSource is one source a listener receives events from
+type listenSource struct {
+	ingestionID string
+	id          uint64
+	projectID   uint64
+	slug        string
+
+	// Where events of this source are delivered: its --route targets, or the
+	// --forward-to and --exec targets when no route matches it
+	targets []string
+
+	// Set when the source has connections, so attempts are polled instead of events
+	connection *api.Connection
+
+	// Last forwarded event in --resume mode, guarded by listener.mu
+	checkpoint *config.Checkpoint
+
+	// In --resume mode, the created_at of every event queued or being delivered by ID,
+	// and the delivered events the checkpoint cannot move to yet because an older one
+	// is still in flight. Guarded by listener.mu.
+	inFlight  map[string]time.Time
+	delivered []config.Checkpoint
+}
+
+// resolveSources looks up the sources given with --source, or every source of the
+// project with --all-sources. With --project, --source also accepts source slugs and
+// only that project is searched.
+func resolveSources(client *api.Client, refs []string, projectRef string, all bool) ([]*listenSource, error) {
+	if all && projectRef == "" {
+		return nil, fmt.Errorf("--all-sources requires --project")
+	}
+
+	if projectRef == "" {
+		var sources []*listenSource
+		seen := make(map[uint64]bool)
+		for _, ref := range refs {
+			found, err := client.GetSourceByIngestionIDWithProject(ref)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get source: %w", err)
+			}
+			if seen[found.Source.ID] {
+				continue
+			}
+			seen[found.Source.ID] = true
+			sources = append(sources, newListenSource(found.Source, found.ProjectID))
+		}
+		return sources, nil
+	}
+
+	project, err := findProject(client, projectRef)
+	if err != nil {
+		return nil, err
+	}
+	projectSources, err := client.GetSources(project.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sources of project '%s': %w", project.Name, err)
+	}
+
+	var sources []*listenSource
+	if all {
+		for i := range projectSources {
+			sources = append(sources, newListenSource(&projectSources[i], project.ID))
+		}
+		if len(sources) == 0 {
+			return nil, fmt.Errorf("project '%s' has no sources", project.Name)
+		}
+		return sources, nil
+	}
+
+	seen := make(map[uint64]bool)
+	for _, ref := range refs {
+		var source *api.Source
+		for i := range projectSources {
+			if projectSources[i].IngestionID == ref || projectSources[i].Slug == ref {
+				source = &projectSources[i]
+				break
+			}
+		}
+		if source == nil {
+			return nil, fmt.Errorf("source '%s' not found in project '%s'", ref, project.Name)
+		}
+		if seen[source.ID] {
+			continue
+		}
+		seen[source.ID] = true
+		sources = append(sources, newListenSource(source, project.ID))
+	}
+	return sources, nil
+}
+
+func newListenSource(source *api.Source, projectID uint64) *listenSource {
+	return &listenSource{
+		ingestionID: source.IngestionID,
+		id:          source.ID,
+		projectID:   projectID,
+		slug:        source.Slug,
+	}
+}
+
+// findProject looks a project up by ID or by name
+func findProject(client *api.Client, ref string) (*api.Project, error) {
+	projects, err := client.GetProjects()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get projects: %w", err)
+	}
+
+	id, idErr := strconv.ParseUint(ref, 10, 64)
+	for i := range projects {
+		if (idErr == nil && projects[i].ID == id) || strings.EqualFold(projects[i].Name, ref) {
+			return &projects[i], nil
+		}
+	}
+	return nil, fmt.Errorf("project '%s' not found", ref)
+}
+
+// chooseConnection picks the connection a source is listened to on, or nil for direct
+// event mode. A connection named by refs wins; a source with several connections and no
+// match is asked about when stdin is a terminal and otherwise falls back to the first
+// one with a warning. required rejects sources without connections (--mode connection).
+func chooseConnection(src *listenSource, connections []api.Connection, refs []string, required bool) (*api.Connection, error) {
+	var matched []api.Connection
+	for _, conn := range connections {
+		if connectionMatches(conn, refs) {
+			matched = append(matched, conn)
+		}
+	}
+
+	switch {
+	case len(matched) == 1:
+		return &matched[0], nil
+	case len(matched) > 1:
+		return nil, fmt.Errorf("--connection matches %d connections of source '%s'; use the connection ID instead", len(matched), src.slug)
+	case len(connections) == 0:
+		if required {
+			return nil, fmt.Errorf("source '%s' has no connections; use --mode direct", src.slug)
+		}
+		return nil, nil
+	case len(connections) == 1:
+		return &connections[0], nil
+	}
+
+	if term.IsTerminal(int(os.Stdin.Fd())) {
+		return pickConnection(src, connections, os.Stdin)
+	}
+	fmt.Fprintf(os.Stderr, "Warning: source '%s' has %d connections, listening on %s (ID: %d); choose one with --connection\n",
+		src.slug, len(connections), connections[0].Name, connections[0].ID)
+	return &connections[0], nil
+}
+
+// pickConnection asks which of a source's connections to listen on
+func pickConnection(src *listenSource, connections []api.Connection, in io.Reader) (*api.Connection, error) {
+	fmt.Printf("Source '%s' has %d connections:\n", src.slug, len(connections))
+	for i, conn := range connections {
+		fmt.Printf("  %d) %s (ID: %d)", i+1, conn.Name, conn.ID)
+		if conn.DestinationURL != "" {
+			fmt.Printf(" -> %s", conn.DestinationURL)
+		}
+		fmt.Println()
+	}
+
+	reader := bufio.NewReader(in)
+	for {
+		fmt.Printf("Choose a connection [1-%d]: ", len(connections))
+		line, err := reader.ReadString('\n')
+		if n, convErr := strconv.Atoi(strings.TrimSpace(line)); convErr == nil && n >= 1 && n <= len(connections) {
+			return &connections[n-1], nil
+		}
+		if err != nil {
+			fmt.Println()
+			return nil, fmt.Errorf("no connection chosen for source '%s'", src.slug)
+		}
+	}
+}
+
+func connectionMatches(conn api.Connection, refs []string) bool {
+	for _, ref := range refs {
+		if ref == conn.Name || ref == strconv.FormatUint(conn.ID, 10) {
+			return true
+		}
+	}
+	return false
+}
+
+// unusedConnectionRefs returns the --connection values that match no chosen connection
+func unusedConnectionRefs(refs []string, sources []*listenSource) []string {
+	var unused []string
+	for _, ref := range refs {
+		matched := false
+		for _, src := range sources {
+			if src.connection != nil && connectionMatches(*src.connection, []string{ref}) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			unused = append(unused, ref)
+		}
+	}
+	return unused
+}
+
+// sourceRoute sends the events of one source, given by slug or ingestion ID, to a target
+type sourceRoute struct {
+	source string
+	target string
+}
+
+type sourceRoutes []sourceRoute
+
+// parseRoutes parses --route values of the form source=target
+func parseRoutes(specs []string) (sourceRoutes, error) {
+	routes := make(sourceRoutes, 0, len(specs))
+	for _, spec := range specs {
+		source, target, ok := strings.Cut(spec, "=")
+		source, target = strings.TrimSpace(source), strings.TrimSpace(target)
+		if !ok || source == "" || target == "" {
+			return nil, fmt.Errorf("invalid --route '%s': expected <source>=<url>", spec)
+		}
+		routes = append(routes, sourceRoute{source: source, target: target})
+	}
+	return routes, nil
+}
+
+func (r sourceRoute) matches(src *listenSource) bool {
+	return r.source == src.slug || r.source == src.ingestionID
+}
+
+// targetsFor returns the routed targets of a source, or defaults when no route matches it
+func (routes sourceRoutes) targetsFor(src *listenSource, defaults []string) []string {
+	var targets []string
+	for _, r := range routes {
+		if r.matches(src) {
+			targets = append(targets, r.target)
+		}
+	}
+	if len(targets) == 0 {
+		return defaults
+	}
+	return targets
+}
+
+// unused returns the routes that match none of the sources, which are most likely typos
+func (routes sourceRoutes) unused(sources []*listenSource) []string {
+	var unused []string
+	for _, r := range routes {
+		matched := false
+		for _, src := range sources {
+			if r.matches(src) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			unused = append(unused, r.source)
+		}
+	}
+	return unused
+}
+
+// sourceSlugs returns the slugs of the sources being listened to, in a stable order
+func (l *listener) sourceSlugs() []string {
+	slugs := make([]string, 0, len(l.sources))
+	for _, src := range l.sources {
+		slugs = append(slugs, src.slug)
+	}
+	sort.Strings(slugs)
+	return slugs
+}
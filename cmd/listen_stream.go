@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/volleyhq/volley-cli/internal/api"
+)
+
+const (
+	streamInitialBackoff = 1 * time.Second
+	streamMaxBackoff     = 30 * time.Second
+)
+
+// runStream keeps a push connection to the event stream of a source open, reconnecting with
+// exponential backoff. Received events are sent on events. Every connection state change
+// is reported on status: nil once connected, the disconnect error when the stream drops,
+// and api.ErrStreamUnsupported (after which runStream returns) if the server has no stream.
+func (l *listener) runStream(ctx context.Context, src *listenSource, events chan<- api.Event, status chan<- error) {
+	backoff := streamInitialBackoff
+	lastEventID := ""
+
+	for {
+		stream, err := l.apiClient.OpenEventStream(ctx, src.projectID, src.id, lastEventID)
+		if errors.Is(err, api.ErrStreamUnsupported) {
+			sendStatus(ctx, status, err)
+			return
+		}
+
+		if err == nil {
+			sendStatus(ctx, status, nil)
+			backoff = streamInitialBackoff
+
+			for {
+				var event *api.Event
+				event, err = stream.Next()
+				if err != nil {
+					break
+				}
+				select {
+				case events <- *event:
+				case <-ctx.Done():
+				}
+			}
+			lastEventID = stream.LastEventID()
+			stream.Close()
+		}
+
+		if ctx.Err() != nil {
+			return
+		}
+		sendStatus(ctx, status, err)
+
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return
+		}
+		backoff *= 2
+		if backoff > streamMaxBackoff {
+			backoff = streamMaxBackoff
+		}
+	}
+}
+
+func sendStatus(ctx context.Context, status chan<- error, err error) {
+	select {
+	case status <- err:
+	case <-ctx.Done():
+	}
+}
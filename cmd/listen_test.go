@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/volleyhq/volley-cli/internal/api"
+	"github.com/volleyhq/volley-cli/internal/config"
+)
+
+func TestRequestTarget(t *testing.T) {
+	tests := []struct {
+		name       string
+		target     string
+		method     string
+		path       string
+		query      string
+		preserve   bool
+		wantMethod string
+		wantURL    string
+	}{
+		{name: "not preserved", target: "http://localhost:3000/hook", method: "PUT", path: "/a", query: "x=1", wantMethod: "POST", wantURL: "http://localhost:3000/hook"},
+		{name: "no path", target: "http://localhost:3000/hook", preserve: true, wantMethod: "POST", wantURL: "http://localhost:3000/hook"},
+		{name: "root path", target: "http://localhost:3000/hook", path: "/", preserve: true, wantMethod: "POST", wantURL: "http://localhost:3000/hook"},
+		{name: "sub-path", target: "http://localhost:3000/hook", method: "PUT", path: "/orders/1", preserve: true, wantMethod: "PUT", wantURL: "http://localhost:3000/hook/orders/1"},
+		{name: "trailing slash on target", target: "http://localhost:3000/hook/", path: "/orders", preserve: true, wantMethod: "POST", wantURL: "http://localhost:3000/hook/orders"},
+		{name: "trailing slash on path", target: "http://localhost:3000/hook", path: "/orders/", preserve: true, wantMethod: "POST", wantURL: "http://localhost:3000/hook/orders/"},
+		{name: "path without leading slash", target: "http://localhost:3000", path: "orders", preserve: true, wantMethod: "POST", wantURL: "http://localhost:3000/orders"},
+		{name: "encoded space", target: "http://localhost:3000/hook", path: "/a%20b", preserve: true, wantMethod: "POST", wantURL: "http://localhost:3000/hook/a%20b"},
+		{name: "encoded slash", target: "http://localhost:3000/hook", path: "/a%2Fb", preserve: true, wantMethod: "POST", wantURL: "http://localhost:3000/hook/a%2Fb"},
+		{name: "encoded target path", target: "http://localhost:3000/my%20hooks", path: "/a%2Fb", preserve: true, wantMethod: "POST", wantURL: "http://localhost:3000/my%20hooks/a%2Fb"},
+		{name: "invalid escaping", target: "http://localhost:3000/hook", path: "/100%", preserve: true, wantMethod: "POST", wantURL: "http://localhost:3000/hook/100%25"},
+		{name: "query", target: "http://localhost:3000/hook", query: "a=1&b=%20", preserve: true, wantMethod: "POST", wantURL: "http://localhost:3000/hook?a=1&b=%20"},
+		{name: "merged query", target: "http://localhost:3000/hook?token=x", path: "/orders", query: "a=1", preserve: true, wantMethod: "POST", wantURL: "http://localhost:3000/hook/orders?token=x&a=1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			event := &api.Event{Method: tt.method, Path: tt.path, QueryString: tt.query}
+			method, got, err := requestTarget(event, tt.target, forwardOptions{preserveRequest: tt.preserve})
+			if err != nil {
+				t.Fatalf("requestTarget() error = %v", err)
+			}
+			if method != tt.wantMethod || got != tt.wantURL {
+				t.Errorf("requestTarget() = %s %s, want %s %s", method, got, tt.wantMethod, tt.wantURL)
+			}
+		})
+	}
+
+	if _, _, err := requestTarget(&api.Event{}, "http://[::1", forwardOptions{preserveRequest: true}); err == nil {
+		t.Error("requestTarget() with an invalid target URL succeeded, want an error")
+	}
+}
+
+func TestFinishKeepsCheckpointBehindInFlightEvents(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	events := make([]*api.Event, 4)
+	src := &listenSource{id: 1, slug: "src", inFlight: make(map[string]time.Time)}
+	for i := range events {
+		events[i] = &api.Event{EventID: fmt.Sprintf("evt_%d", i), SourceID: src.id, CreatedAt: base.Add(time.Duration(i) * time.Second)}
+		src.inFlight[events[i].EventID] = events[i].CreatedAt
+	}
+	l := &listener{sources: map[uint64]*listenSource{src.id: src}, resume: true}
+
+	steps := []struct {
+		event   int
+		handled bool
+		want    string // checkpoint event ID after the step, empty for none
+	}{
+		// A newer event finishing first must not move the checkpoint past evt_0 and evt_1
+		{event: 2, handled: true, want: ""},
+		// A failed event is never a checkpoint, but no longer holds the others back
+		{event: 0, handled: false, want: ""},
+		{event: 1, handled: true, want: "evt_2"},
+		{event: 3, handled: true, want: "evt_3"},
+	}
+	for _, step := range steps {
+		l.finish(events[step.event], step.handled)
+		got := ""
+		if src.checkpoint != nil {
+			got = src.checkpoint.EventID
+		}
+		if got != step.want {
+			t.Fatalf("checkpoint after finishing evt_%d = %q, want %q", step.event, got, step.want)
+		}
+	}
+
+	saved, err := config.LoadCheckpoint(src.id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if saved == nil || saved.EventID != "evt_3" || !saved.CreatedAt.Equal(events[3].CreatedAt) {
+		t.Errorf("saved checkpoint = %+v, want evt_3", saved)
+	}
+}
@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/volleyhq/volley-cli/internal/api"
+)
+
+// transformTimeout bounds how long a --transform command may take for one event
+const transformTimeout = 30 * time.Second
+
+// shellCommand runs command through the platform shell so users can pass pipelines
+// and arguments the way they would type them
+func shellCommand(ctx context.Context, command string) *exec.Cmd {
+	if runtime.GOOS == "windows" {
+		return exec.CommandContext(ctx, "cmd", "/C", command)
+	}
+	return exec.CommandContext(ctx, "sh", "-c", command)
+}
+
+// transformEvent pipes an event as JSON through command and returns the event it prints.
+// Fields missing from the output keep their original value and the event ID cannot be
+// changed, since it ties deliveries to the original event. It returns nil when the
+// command prints nothing, which drops the event.
+func transformEvent(ctx context.Context, command string, event *api.Event) (*api.Event, error) {
+	ctx, cancel := context.WithTimeout(ctx, transformTimeout)
+	defer cancel()
+
+	input, err := json.Marshal(event)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode event: %w", err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := shellCommand(ctx, command)
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("transform failed: %w: %s", err, msg)
+		}
+		return nil, fmt.Errorf("transform failed: %w", err)
+	}
+
+	if len(bytes.TrimSpace(stdout.Bytes())) == 0 {
+		return nil, nil
+	}
+	transformed := *event
+	if err := json.Unmarshal(stdout.Bytes(), &transformed); err != nil {
+		return nil, fmt.Errorf("transform printed invalid event JSON: %w", err)
+	}
+	transformed.EventID = event.EventID
+	return &transformed, nil
+}
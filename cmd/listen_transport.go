@@ -0,0 +1,125 @@
+package cmd
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// unixTargetPrefix marks targets served on a Unix domain socket:
+// unix:///path/to/app.sock or unix:///path/to/app.sock:/webhook?x=1
+const unixTargetPrefix = "unix://"
+
+// forwardTimeout bounds a single request to a local target
+const forwardTimeout = 10 * time.Second
+
+// tlsOptions configure how HTTPS targets are trusted and authenticated
+type tlsOptions struct {
+	insecure   bool
+	caCert     string
+	clientCert string
+	clientKey  string
+}
+
+// config returns the TLS configuration for local targets, or nil for Go's defaults
+func (o tlsOptions) config() (*tls.Config, error) {
+	if !o.insecure && o.caCert == "" && o.clientCert == "" && o.clientKey == "" {
+		return nil, nil
+	}
+	if (o.clientCert == "") != (o.clientKey == "") {
+		return nil, fmt.Errorf("--client-cert and --client-key must be used together")
+	}
+
+	cfg := &tls.Config{InsecureSkipVerify: o.insecure}
+	if o.caCert != "" {
+		pem, err := os.ReadFile(o.caCert)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read --ca-cert: %w", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in --ca-cert %s", o.caCert)
+		}
+		cfg.RootCAs = pool
+	}
+	if o.clientCert != "" {
+		cert, err := tls.LoadX509KeyPair(o.clientCert, o.clientKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+	return cfg, nil
+}
+
+// forwardClients hands out HTTP clients for local targets. Clients are reused so
+// connections to a target are kept alive between events.
+type forwardClients struct {
+	tls *tls.Config
+
+	mu   sync.Mutex
+	http *http.Client
+	unix map[string]*http.Client // by socket path
+}
+
+// defaultClients serves targets when no TLS options were given
+var defaultClients = newForwardClients(nil)
+
+func newForwardClients(tlsConfig *tls.Config) *forwardClients {
+	return &forwardClients{tls: tlsConfig, unix: make(map[string]*http.Client)}
+}
+
+// forTarget returns the client for a target and the HTTP URL to request from it
+func (c *forwardClients) forTarget(target string) (*http.Client, string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	rest, ok := strings.CutPrefix(target, unixTargetPrefix)
+	if !ok {
+		if c.http == nil {
+			c.http = c.newClient(nil)
+		}
+		return c.http, target, nil
+	}
+
+	socket, path, _ := strings.Cut(rest, ":")
+	if socket == "" {
+		return nil, "", fmt.Errorf("invalid target %q: expected unix:///path/to/socket[:/path]", target)
+	}
+	if path == "" {
+		path = "/"
+	}
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+
+	client, ok := c.unix[socket]
+	if !ok {
+		dialer := &net.Dialer{Timeout: forwardTimeout}
+		client = c.newClient(func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return dialer.DialContext(ctx, "unix", socket)
+		})
+		c.unix[socket] = client
+	}
+	// The host is only used for the Host header, the socket decides where the request goes
+	return client, "http://localhost" + path, nil
+}
+
+func (c *forwardClients) newClient(dial func(ctx context.Context, network, addr string) (net.Conn, error)) *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = c.tls
+	if dial != nil {
+		transport.DialContext = dial
+	}
+	return &http.Client{Timeout: forwardTimeout, Transport: transport}
+}
@@ -0,0 +1,505 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"golang.org/x/term"
+)
+
+// uiLogLines is the number of captured output lines kept for the log pane
+const uiLogLines = 100
+
+// uiLogRows is the number of log lines shown below the event table
+const uiLogRows = 4
+
+// uiRefreshInterval is how often the dashboard is redrawn to pick up new events
+const uiRefreshInterval = 500 * time.Millisecond
+
+// Keys the dashboard reacts to, after escape sequences have been decoded
+const (
+	keyUp = iota + 256
+	keyDown
+	keyEscape
+	keyEnter
+	keyBackspace
+	keyCtrlC
+)
+
+// listenUI is the interactive terminal dashboard of 'volley listen --ui'. It owns the
+// terminal while running: everything the listener prints is captured into a log pane.
+type listenUI struct {
+	l    *listener
+	ctx  context.Context // used for re-forwarded events
+	quit chan<- os.Signal
+
+	tty      *os.File // the terminal, i.e. the original stdout
+	stderr   *os.File
+	state    *term.State
+	logPipe  *os.File
+	logsDone chan struct{}
+	done     chan struct{}
+	stopped  chan struct{}
+
+	logMu sync.Mutex
+	logs  []string
+
+	// View state, only touched by the run loop
+	selectedID string
+	filter     string
+	editing    bool
+	detail     bool
+	scroll     int
+}
+
+// startListenUI switches the terminal to the dashboard. Quitting sends os.Interrupt on
+// quit so the listener shuts down exactly as it does on Ctrl+C.
+func startListenUI(l *listener, ctx context.Context, quit chan<- os.Signal) (*listenUI, error) {
+	u := &listenUI{
+		l:        l,
+		ctx:      ctx,
+		quit:     quit,
+		tty:      os.Stdout,
+		stderr:   os.Stderr,
+		logsDone: make(chan struct{}),
+		done:     make(chan struct{}),
+		stopped:  make(chan struct{}),
+	}
+
+	state, err := term.MakeRaw(int(os.Stdin.Fd()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to set up terminal: %w", err)
+	}
+	u.state = state
+
+	// Capture output of the listener so it does not tear through the dashboard
+	r, w, err := os.Pipe()
+	if err != nil {
+		term.Restore(int(os.Stdin.Fd()), state)
+		return nil, fmt.Errorf("failed to capture output: %w", err)
+	}
+	u.logPipe = w
+	os.Stdout = w
+	os.Stderr = w
+	go u.captureLogs(r)
+
+	// Alternate screen, hidden cursor
+	fmt.Fprint(u.tty, "\x1b[?1049h\x1b[?25l")
+
+	keys := make(chan int)
+	go readKeys(keys)
+	go u.run(keys)
+	return u, nil
+}
+
+// stop restores the terminal and prints the captured log lines to it
+func (u *listenUI) stop() {
+	close(u.done)
+	<-u.stopped
+
+	os.Stdout = u.tty
+	os.Stderr = u.stderr
+	u.logPipe.Close()
+	<-u.logsDone
+
+	fmt.Fprint(u.tty, "\x1b[?25h\x1b[?1049l")
+	term.Restore(int(os.Stdin.Fd()), u.state)
+
+	u.logMu.Lock()
+	defer u.logMu.Unlock()
+	for _, line := range u.logs {
+		fmt.Fprintln(u.tty, line)
+	}
+}
+
+// captureLogs keeps the most recent lines written to the captured stdout and stderr
+func (u *listenUI) captureLogs(r *os.File) {
+	defer close(u.logsDone)
+	defer r.Close()
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		u.logMu.Lock()
+		u.logs = append(u.logs, scanner.Text())
+		if len(u.logs) > uiLogLines {
+			u.logs = u.logs[len(u.logs)-uiLogLines:]
+		}
+		u.logMu.Unlock()
+	}
+}
+
+// readKeys decodes key presses from the raw terminal. It runs until the process exits.
+func readKeys(keys chan<- int) {
+	buf := make([]byte, 64)
+	for {
+		n, err := os.Stdin.Read(buf)
+		if err != nil {
+			return
+		}
+		for i := 0; i < n; i++ {
+			switch b := buf[i]; {
+			case b == 0x1b && i+2 < n && buf[i+1] == '[':
+				switch buf[i+2] {
+				case 'A':
+					keys <- keyUp
+				case 'B':
+					keys <- keyDown
+				}
+				i += 2
+			case b == 0x1b:
+				keys <- keyEscape
+			case b == '\r' || b == '\n':
+				keys <- keyEnter
+			case b == 0x7f || b == 0x08:
+				keys <- keyBackspace
+			case b == 0x03:
+				keys <- keyCtrlC
+			default:
+				keys <- int(b)
+			}
+		}
+	}
+}
+
+// run redraws the dashboard on every key press and periodically for new events
+func (u *listenUI) run(keys <-chan int) {
+	defer close(u.stopped)
+
+	ticker := time.NewTicker(uiRefreshInterval)
+	defer ticker.Stop()
+
+	u.draw()
+	for {
+		select {
+		case <-u.done:
+			return
+		case key := <-keys:
+			u.handleKey(key)
+		case <-ticker.C:
+		}
+		u.draw()
+	}
+}
+
+func (u *listenUI) handleKey(key int) {
+	if key == keyCtrlC {
+		u.requestQuit()
+		return
+	}
+
+	if u.editing {
+		switch key {
+		case keyEnter:
+			u.editing = false
+		case keyEscape:
+			u.editing = false
+			u.filter = ""
+		case keyBackspace:
+			if r := []rune(u.filter); len(r) > 0 {
+				u.filter = string(r[:len(r)-1])
+			}
+		default:
+			if key >= 0x20 && key < 0x7f {
+				u.filter += string(rune(key))
+			}
+		}
+		return
+	}
+
+	switch key {
+	case keyUp, 'k':
+		if u.detail {
+			u.scroll = max(u.scroll-1, 0)
+		} else {
+			u.moveSelection(-1)
+		}
+	case keyDown, 'j':
+		if u.detail {
+			u.scroll++
+		} else {
+			u.moveSelection(1)
+		}
+	case keyEnter:
+		if u.selectedID != "" {
+			u.detail = !u.detail
+			u.scroll = 0
+		}
+	case keyEscape:
+		if u.detail {
+			u.detail = false
+		} else {
+			u.filter = ""
+		}
+	case 'q':
+		if u.detail {
+			u.detail = false
+		} else {
+			u.requestQuit()
+		}
+	case 'r':
+		u.l.replay(u.ctx, u.selectedID)
+	case 'p':
+		if u.l.dispatcher.paused() {
+			u.l.dispatcher.resume()
+			fmt.Println("Forwarding resumed")
+		} else {
+			u.l.dispatcher.pause()
+			fmt.Println("Forwarding paused, new events are queued")
+		}
+	case '/':
+		if !u.detail {
+			u.editing = true
+		}
+	}
+}
+
+// requestQuit shuts the listener down like Ctrl+C; pressing it twice aborts in-flight deliveries
+func (u *listenUI) requestQuit() {
+	select {
+	case u.quit <- os.Interrupt:
+	default:
+	}
+}
+
+// visibleRecords returns the records matching the filter, newest first
+func (u *listenUI) visibleRecords() []eventRecord {
+	records := u.l.history.list()
+	filter := strings.ToLower(u.filter)
+
+	visible := make([]eventRecord, 0, len(records))
+	for i := len(records) - 1; i >= 0; i-- {
+		if filter == "" || recordMatches(&records[i], filter) {
+			visible = append(visible, records[i])
+		}
+	}
+	return visible
+}
+
+// recordMatches reports whether the event ID, source, status or body contains filter
+func recordMatches(record *eventRecord, filter string) bool {
+	status, _ := recordStatus(record)
+	for _, field := range []string{record.Event.EventID, record.Event.SourceSlug, status, record.Event.RawBody} {
+		if strings.Contains(strings.ToLower(field), filter) {
+			return true
+		}
+	}
+	return false
+}
+
+// recordStatus summarizes the latest delivery of an event as status and latency columns
+func recordStatus(record *eventRecord) (string, string) {
+	if len(record.Deliveries) == 0 {
+		return "pending", ""
+	}
+	d := record.Deliveries[len(record.Deliveries)-1]
+	status := "error"
+	switch {
+	case d.StatusCode != 0:
+		status = fmt.Sprint(d.StatusCode)
+	case d.Error == "":
+		// Commands have no status code
+		status = "ok"
+	}
+	return status, fmt.Sprintf("%dms", d.LatencyMs)
+}
+
+func (u *listenUI) moveSelection(delta int) {
+	records := u.visibleRecords()
+	if len(records) == 0 {
+		return
+	}
+	i := selectedIndex(records, u.selectedID)
+	i = min(max(i+delta, 0), len(records)-1)
+	u.selectedID = records[i].Event.EventID
+}
+
+// selectedIndex returns the position of the selected event, or 0 if it is not listed
+func selectedIndex(records []eventRecord, id string) int {
+	for i := range records {
+		if records[i].Event.EventID == id {
+			return i
+		}
+	}
+	return 0
+}
+
+func (u *listenUI) draw() {
+	width, height, err := term.GetSize(int(u.tty.Fd()))
+	if err != nil || width <= 0 || height <= 0 {
+		width, height = 80, 24
+	}
+
+	records := u.visibleRecords()
+	selected := selectedIndex(records, u.selectedID)
+	if len(records) > 0 {
+		u.selectedID = records[selected].Event.EventID
+	} else {
+		u.selectedID = ""
+		u.detail = false
+	}
+
+	header := fmt.Sprintf("Volley listen · %s · %d event(s)", strings.Join(u.l.sourceSlugs(), ", "), len(records))
+	if u.l.dispatcher.paused() {
+		header += " · PAUSED"
+	}
+	if u.filter != "" {
+		header += fmt.Sprintf(" · filter: %q", u.filter)
+	}
+	lines := []string{"\x1b[1m" + truncate(header, width) + "\x1b[0m"}
+
+	bodyRows := max(height-uiLogRows-3, 1)
+	if u.detail {
+		detail := detailLines(&records[selected])
+		u.scroll = min(u.scroll, max(len(detail)-bodyRows, 0))
+		for _, line := range detail[u.scroll:min(u.scroll+bodyRows, len(detail))] {
+			lines = append(lines, truncate(line, width))
+		}
+	} else {
+		lines = append(lines, "\x1b[2m"+truncate(fmt.Sprintf("%-8s  %-36s  %-14s  %-7s  %s", "TIME", "EVENT ID", "SOURCE", "STATUS", "LATENCY"), width)+"\x1b[0m")
+		tableRows := bodyRows - 1
+		offset := max(selected-tableRows+1, 0)
+		for i := offset; i < len(records) && i < offset+tableRows; i++ {
+			record := &records[i]
+			status, latency := recordStatus(record)
+			row := truncate(fmt.Sprintf("%-8s  %-36s  %-14s  %-7s  %s",
+				record.ReceivedAt.Format("15:04:05"), record.Event.EventID, record.Event.SourceSlug, status, latency), width)
+			if i == selected {
+				row = "\x1b[7m" + row + "\x1b[0m"
+			}
+			lines = append(lines, row)
+		}
+	}
+	for len(lines) < bodyRows+1 {
+		lines = append(lines, "")
+	}
+
+	lines = append(lines, "\x1b[2m"+strings.Repeat("─", width)+"\x1b[0m")
+	u.logMu.Lock()
+	logs := u.logs[max(len(u.logs)-uiLogRows, 0):]
+	for _, line := range logs {
+		lines = append(lines, truncate(line, width))
+	}
+	u.logMu.Unlock()
+	for len(lines) < height-1 {
+		lines = append(lines, "")
+	}
+
+	var help string
+	switch {
+	case u.editing:
+		help = "Filter: " + u.filter + "█"
+	case u.detail:
+		help = "↑/↓ scroll · r re-forward · esc back"
+	default:
+		help = "↑/↓ select · enter inspect · r re-forward · p pause/resume · / filter · q quit"
+	}
+	lines = append(lines, "\x1b[2m"+truncate(help, width)+"\x1b[0m")
+
+	var b strings.Builder
+	b.WriteString("\x1b[H")
+	for i, line := range lines[:min(len(lines), height)] {
+		if i > 0 {
+			b.WriteString("\r\n")
+		}
+		b.WriteString(line)
+		b.WriteString("\x1b[K")
+	}
+	b.WriteString("\x1b[J")
+	fmt.Fprint(u.tty, b.String())
+}
+
+// detailLines renders the request of an event and every local response to it
+func detailLines(record *eventRecord) []string {
+	event := &record.Event
+	lines := []string{
+		"Event:    " + event.EventID,
+		fmt.Sprintf("Source:   %s (ID: %d)", event.SourceSlug, event.SourceID),
+		"Received: " + record.ReceivedAt.Format(time.RFC3339),
+	}
+	if event.Method != "" {
+		request := event.Method + " " + event.Path
+		if event.QueryString != "" {
+			request += "?" + event.QueryString
+		}
+		lines = append(lines, "Request:  "+request)
+	}
+	if record.Signature != "" {
+		lines = append(lines, "Signature: "+record.Signature)
+	}
+
+	lines = append(lines, "", "Headers:")
+	lines = append(lines, headerLines(event.Headers)...)
+	lines = append(lines, "", "Body:")
+	lines = append(lines, indentLines(prettyBody(event.RawBody))...)
+
+	lines = append(lines, "", fmt.Sprintf("Deliveries (%d):", len(record.Deliveries)))
+	for _, d := range record.Deliveries {
+		line := fmt.Sprintf("  %s → %s", d.DeliveredAt.Format("15:04:05"), d.Target)
+		if d.StatusCode != 0 {
+			line += fmt.Sprintf(" [%d, %dms]", d.StatusCode, d.LatencyMs)
+		}
+		if d.Error != "" {
+			line += ": " + d.Error
+		}
+		lines = append(lines, line)
+		lines = append(lines, indentLines(strings.Join(headerLines(d.Headers), "\n"))...)
+		if d.Body != "" {
+			lines = append(lines, "")
+			lines = append(lines, indentLines(prettyBody(d.Body))...)
+		}
+	}
+	return lines
+}
+
+// headerLines formats headers as sorted "Key: value" lines
+func headerLines(headers map[string][]string) []string {
+	keys := make([]string, 0, len(headers))
+	for key := range headers {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var lines []string
+	for _, key := range keys {
+		for _, value := range headers[key] {
+			lines = append(lines, fmt.Sprintf("  %s: %s", key, value))
+		}
+	}
+	return lines
+}
+
+// indentLines splits text into lines indented by two spaces
+func indentLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	lines := strings.Split(strings.TrimRight(text, "\n"), "\n")
+	for i, line := range lines {
+		lines[i] = "  " + line
+	}
+	return lines
+}
+
+// truncate cuts a line to the terminal width and drops characters that would break the
+// layout. Other control characters, ESC in particular, are replaced so bodies and headers
+// sent by third parties cannot move the cursor or restyle the terminal.
+func truncate(s string, width int) string {
+	s = strings.NewReplacer("\t", "    ", "\r", "").Replace(s)
+	r := []rune(s)
+	for i, c := range r {
+		if unicode.IsControl(c) {
+			r[i] = unicode.ReplacementChar
+		}
+	}
+	if len(r) > width {
+		r = r[:width]
+	}
+	return string(r)
+}
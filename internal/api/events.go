@@ -15,6 +15,11 @@ type Event struct {
 	CreatedAt  time.Time             `json:"created_at"`
 	EntryTime  *time.Time            `json:"entry_time"`
 	ExitTime   *time.Time            `json:"exit_time"`
+	// Original request line as received by the ingestion endpoint. Path is the part
+	// after /hook/<ingestion_id> and QueryString is the raw query without the leading '?'.
+	Method      string `json:"method,omitempty"`
+	Path        string `json:"path,omitempty"`
+	QueryString string `json:"query_string,omitempty"`
 }
 
 type TriggerResponse struct {
@@ -43,6 +48,9 @@ type PayloadResponseItem struct {
 	Headers        map[string]interface{} `json:"headers"` // API returns as map[string]interface{}
 	Remarks        *string                `json:"remarks"`
 	CreatedAt      time.Time              `json:"created_at"`
+	Method         string                 `json:"method"`
+	Path           string                 `json:"path"`
+	QueryString    string                 `json:"query_string"`
 }
 
 type PayloadsResponse struct {
@@ -93,6 +101,9 @@ func convertPayloadToEvent(payload PayloadResponseItem) Event {
 		RawBody:    payload.RawBody, // CRITICAL: Preserve raw body exactly (byte-for-byte) for signature validation
 		Headers:    headers,         // CRITICAL: Preserve headers exactly for signature validation
 		CreatedAt:  payload.CreatedAt,
+		Method:      payload.Method,
+		Path:        payload.Path,
+		QueryString: payload.QueryString,
 	}
 }
 
@@ -113,7 +124,8 @@ func (c *Client) GetEvents(projectID uint64, limit int) ([]Event, error) {
 
 // GetEventsBySource gets events for a specific source with optional time filtering
 // This is more efficient than GetEvents + filtering client-side
-func (c *Client) GetEventsBySource(projectID uint64, sourceID uint64, limit int, startTime *time.Time) ([]Event, error) {
+// Events are returned newest first; endTime pages backwards through older events
+func (c *Client) GetEventsBySource(projectID uint64, sourceID uint64, limit int, startTime, endTime *time.Time) ([]Event, error) {
 	var resp PayloadsResponse
 	
 	// Build query parameters with proper URL encoding
@@ -123,6 +135,10 @@ func (c *Client) GetEventsBySource(projectID uint64, sourceID uint64, limit int,
 	if startTime != nil {
 		params.Set("start_time", startTime.Format(time.RFC3339))
 	}
+	if endTime != nil {
+		// Keep sub-second precision so a page boundary does not skip events within a second
+		params.Set("end_time", endTime.Format(time.RFC3339Nano))
+	}
 	
 	path := fmt.Sprintf("/api/projects/%d/payloads?%s", projectID, params.Encode())
 	
@@ -150,6 +166,9 @@ func (c *Client) GetEvent(eventID string, projectID uint64) (*Event, error) {
 			RawBody     string                   `json:"raw_body"`
 			Headers     map[string]interface{}    `json:"headers"`
 			CreatedAt   string                   `json:"created_at"`
+			Method      string                   `json:"method"`
+			Path        string                   `json:"path"`
+			QueryString string                   `json:"query_string"`
 		} `json:"requests"`
 	}
 
@@ -194,6 +213,9 @@ func (c *Client) GetEvent(eventID string, projectID uint64) (*Event, error) {
 				RawBody:    req.RawBody,
 				Headers:    headers,
 				CreatedAt:  createdAt,
+				Method:      req.Method,
+				Path:        req.Path,
+				QueryString: req.QueryString,
 			}, nil
 		}
 	}
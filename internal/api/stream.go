@@ -0,0 +1,139 @@
+package api
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// ErrStreamUnsupported is returned by OpenEventStream when the server does not offer
+// a push stream, so callers can fall back to polling
+var ErrStreamUnsupported = errors.New("event streaming is not supported by the server")
+
+// EventStream is a long-lived server-sent events connection delivering new events for a source
+type EventStream struct {
+	body        io.ReadCloser
+	reader      *bufio.Reader
+	lastEventID string
+}
+
+// OpenEventStream connects to the server-sent events stream for a source.
+// lastEventID, when set, asks the server to resume after that event.
+func (c *Client) OpenEventStream(ctx context.Context, projectID uint64, sourceID uint64, lastEventID string) (*EventStream, error) {
+	params := url.Values{}
+	params.Set("source_id", fmt.Sprintf("%d", sourceID))
+	path := fmt.Sprintf("/api/projects/%d/payloads/stream?%s", projectID, params.Encode())
+
+	req, err := http.NewRequestWithContext(ctx, "GET", c.baseURL+path, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "text/event-stream")
+	req.Header.Set("Cache-Control", "no-cache")
+	if lastEventID != "" {
+		req.Header.Set("Last-Event-ID", lastEventID)
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	// The regular client has an overall timeout which would cut the stream off,
+	// so use one without it and rely on ctx for cancellation
+	streamClient := &http.Client{Transport: c.httpClient.Transport}
+	resp, err := streamClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+
+	switch resp.StatusCode {
+	case http.StatusNotFound, http.StatusMethodNotAllowed, http.StatusNotAcceptable, http.StatusNotImplemented:
+		resp.Body.Close()
+		return nil, ErrStreamUnsupported
+	}
+	if resp.StatusCode >= 400 {
+		defer resp.Body.Close()
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("API error (%d): %s", resp.StatusCode, string(bodyBytes))
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if mediaType != "text/event-stream" {
+		resp.Body.Close()
+		return nil, ErrStreamUnsupported
+	}
+
+	return &EventStream{
+		body:        resp.Body,
+		reader:      bufio.NewReader(resp.Body),
+		lastEventID: lastEventID,
+	}, nil
+}
+
+// Next blocks until the next event arrives. Heartbeats and unknown message types are skipped.
+// It returns io.EOF when the server closes the stream.
+func (s *EventStream) Next() (*Event, error) {
+	var data strings.Builder
+	var eventType, id string
+
+	for {
+		line, err := s.reader.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		line = strings.TrimRight(line, "\r\n")
+
+		// A blank line dispatches the message accumulated so far
+		if line == "" {
+			if data.Len() > 0 && (eventType == "" || eventType == "message" || eventType == "payload") {
+				var payload PayloadResponseItem
+				if err := json.Unmarshal([]byte(data.String()), &payload); err != nil {
+					return nil, fmt.Errorf("failed to decode stream message: %w", err)
+				}
+				if id != "" {
+					s.lastEventID = id
+				}
+				event := convertPayloadToEvent(payload)
+				return &event, nil
+			}
+			data.Reset()
+			eventType, id = "", ""
+			continue
+		}
+
+		// Lines starting with a colon are comments, used by servers as heartbeats
+		if strings.HasPrefix(line, ":") {
+			continue
+		}
+
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+		switch field {
+		case "data":
+			if data.Len() > 0 {
+				data.WriteByte('\n')
+			}
+			data.WriteString(value)
+		case "event":
+			eventType = value
+		case "id":
+			id = value
+		}
+	}
+}
+
+// LastEventID returns the ID of the last message received, for resuming after a reconnect
+func (s *EventStream) LastEventID() string {
+	return s.lastEventID
+}
+
+// Close closes the underlying connection
+func (s *EventStream) Close() error {
+	return s.body.Close()
+}
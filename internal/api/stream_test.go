@@ -0,0 +1,83 @@
+package api
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func newTestStream(input, lastEventID string) *EventStream {
+	body := io.NopCloser(strings.NewReader(input))
+	return &EventStream{body: body, reader: bufio.NewReader(body), lastEventID: lastEventID}
+}
+
+func TestEventStreamNext(t *testing.T) {
+	input := ": connected\n" +
+		"\n" +
+		"id: 1\n" +
+		"data: {\"event_id\":\"evt_1\",\"raw_body\":\"{}\"}\n" +
+		"\n" +
+		// A heartbeat comment between messages
+		": ping\n" +
+		"\n" +
+		// Multi-line data is joined with newlines, CRLF line endings are accepted
+		"event: payload\r\n" +
+		"id: 2\r\n" +
+		"data: {\"event_id\":\"evt_2\",\r\n" +
+		"data:\"raw_body\":\"a\"}\r\n" +
+		"\r\n" +
+		// Messages of other types are skipped
+		"event: heartbeat\n" +
+		"id: 3\n" +
+		"data: {}\n" +
+		"\n" +
+		// A message without an id keeps the previous one
+		"data: {\"event_id\":\"evt_4\"}\n" +
+		"\n"
+	s := newTestStream(input, "0")
+
+	want := []struct {
+		eventID     string
+		rawBody     string
+		lastEventID string
+	}{
+		{"evt_1", "{}", "1"},
+		{"evt_2", "a", "2"},
+		{"evt_4", "", "2"},
+	}
+	for _, w := range want {
+		event, err := s.Next()
+		if err != nil {
+			t.Fatalf("Next() error = %v, want event %s", err, w.eventID)
+		}
+		if event.EventID != w.eventID || event.RawBody != w.rawBody {
+			t.Errorf("Next() = %s with body %q, want %s with body %q", event.EventID, event.RawBody, w.eventID, w.rawBody)
+		}
+		if got := s.LastEventID(); got != w.lastEventID {
+			t.Errorf("LastEventID() after %s = %q, want %q", w.eventID, got, w.lastEventID)
+		}
+	}
+
+	if _, err := s.Next(); !errors.Is(err, io.EOF) {
+		t.Errorf("Next() at end of stream error = %v, want io.EOF", err)
+	}
+}
+
+func TestEventStreamNextKeepsLastEventIDOnStart(t *testing.T) {
+	s := newTestStream(": keep-alive\n\n", "evt_resume")
+	if _, err := s.Next(); !errors.Is(err, io.EOF) {
+		t.Fatalf("Next() error = %v, want io.EOF", err)
+	}
+	if got := s.LastEventID(); got != "evt_resume" {
+		t.Errorf("LastEventID() = %q, want %q", got, "evt_resume")
+	}
+}
+
+func TestEventStreamNextInvalidData(t *testing.T) {
+	s := newTestStream("data: not json\n\n", "")
+	if _, err := s.Next(); err == nil || errors.Is(err, io.EOF) {
+		t.Errorf("Next() error = %v, want a decode error", err)
+	}
+}
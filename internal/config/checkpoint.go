@@ -0,0 +1,89 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Checkpoint records the last event forwarded from a source so that a
+// restarted listener can backfill everything that arrived while it was down.
+type Checkpoint struct {
+	EventID   string    `json:"event_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+var checkpointMu sync.Mutex
+
+// LoadCheckpoint returns the stored checkpoint for a source, or nil if there is none
+func LoadCheckpoint(sourceID uint64) (*Checkpoint, error) {
+	checkpointMu.Lock()
+	defer checkpointMu.Unlock()
+
+	checkpoints, err := readCheckpoints()
+	if err != nil {
+		return nil, err
+	}
+	cp, ok := checkpoints[strconv.FormatUint(sourceID, 10)]
+	if !ok {
+		return nil, nil
+	}
+	return &cp, nil
+}
+
+// SaveCheckpoint stores the checkpoint for a source, replacing any previous one
+func SaveCheckpoint(sourceID uint64, cp Checkpoint) error {
+	checkpointMu.Lock()
+	defer checkpointMu.Unlock()
+
+	checkpoints, err := readCheckpoints()
+	if err != nil {
+		return err
+	}
+	checkpoints[strconv.FormatUint(sourceID, 10)] = cp
+
+	if err := os.MkdirAll(Dir(), 0755); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(checkpoints, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal checkpoints: %w", err)
+	}
+
+	// Write to a temp file and rename so a crash never leaves a truncated file behind
+	path := checkpointPath()
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("failed to write checkpoints: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("failed to write checkpoints: %w", err)
+	}
+	return nil
+}
+
+func readCheckpoints() (map[string]Checkpoint, error) {
+	checkpoints := make(map[string]Checkpoint)
+
+	data, err := os.ReadFile(checkpointPath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return checkpoints, nil
+		}
+		return nil, fmt.Errorf("failed to read checkpoints: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &checkpoints); err != nil {
+		return nil, fmt.Errorf("failed to parse checkpoints: %w", err)
+	}
+	return checkpoints, nil
+}
+
+func checkpointPath() string {
+	return filepath.Join(Dir(), "checkpoints.json")
+}
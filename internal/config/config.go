@@ -52,6 +52,11 @@ func (c *Config) Save() error {
 	return nil
 }
 
+// Dir returns the directory holding the config file and other CLI state files
+func Dir() string {
+	return filepath.Dir(getConfigPath())
+}
+
 func getConfigPath() string {
 	home, err := os.UserHomeDir()
 	if err != nil {
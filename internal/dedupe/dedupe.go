@@ -0,0 +1,236 @@
+package dedupe
+
+import (
+	"bufio"
+	"container/list"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Store remembers which event IDs were already handled. It holds at most capacity
+// IDs and, when window is non-zero, forgets IDs older than window, so memory stays
+// flat in long-running listeners. With a backing file the IDs survive restarts.
+//
+// IDs recorded with Mark are pending: they are remembered in memory but only written
+// to the backing file once Add confirms the event was handled, so events still queued
+// when the process dies are fetched again by the next run.
+type Store struct {
+	mu       sync.Mutex
+	capacity int
+	window   time.Duration
+	order    *list.List // oldest first
+	items    map[string]*list.Element
+
+	// Optional on-disk backing: an append-only log compacted once it grows
+	path     string
+	file     *os.File
+	appended int
+}
+
+type entry struct {
+	id      string
+	seenAt  time.Time
+	pending bool
+}
+
+// New returns an in-memory store
+func New(capacity int, window time.Duration) *Store {
+	if capacity < 1 {
+		capacity = 1
+	}
+	return &Store{
+		capacity: capacity,
+		window:   window,
+		order:    list.New(),
+		items:    make(map[string]*list.Element),
+	}
+}
+
+// Open returns a store backed by the file at path, loading the IDs recorded by previous runs
+func Open(path string, capacity int, window time.Duration) (*Store, error) {
+	s := New(capacity, window)
+	s.path = path
+
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, fmt.Errorf("failed to create dedupe directory: %w", err)
+	}
+
+	f, err := os.Open(path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to open dedupe file: %w", err)
+	}
+	if err == nil {
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			// Each line is "<unix seconds> <event id>"; ignore anything malformed
+			ts, id, ok := strings.Cut(scanner.Text(), " ")
+			if !ok || id == "" {
+				continue
+			}
+			secs, err := strconv.ParseInt(ts, 10, 64)
+			if err != nil {
+				continue
+			}
+			s.insert(id, time.Unix(secs, 0), false)
+		}
+		f.Close()
+		if err := scanner.Err(); err != nil {
+			return nil, fmt.Errorf("failed to read dedupe file: %w", err)
+		}
+	}
+
+	// Rewrite the file with only the live entries, then keep appending to it
+	if err := s.compact(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Mark records id as pending without writing it to the backing file. It returns false
+// if id was already seen within the window.
+func (s *Store) Mark(id string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	s.expire(now)
+	if _, ok := s.items[id]; ok {
+		return false
+	}
+	s.insert(id, now, true)
+	return true
+}
+
+// Add records id as handled and writes it to the backing file. It returns false if id
+// was already handled within the window; a pending id is confirmed. A failure to write
+// the backing file is returned, but id is recorded in memory all the same.
+func (s *Store) Add(id string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	s.expire(now)
+	if elem, ok := s.items[id]; ok {
+		e := elem.Value.(*entry)
+		if !e.pending {
+			return false, nil
+		}
+		e.pending = false
+	} else {
+		s.insert(id, now, false)
+	}
+
+	if s.file == nil {
+		return true, nil
+	}
+	var writeErr, compactErr error
+	if _, err := fmt.Fprintf(s.file, "%d %s\n", now.Unix(), id); err != nil {
+		writeErr = fmt.Errorf("failed to record event %s in dedupe file: %w", id, err)
+	}
+	s.appended++
+	if s.appended > 2*s.capacity {
+		compactErr = s.compact()
+	}
+	return true, errors.Join(writeErr, compactErr)
+}
+
+// Seen reports whether id was recorded within the window
+func (s *Store) Seen(id string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.expire(time.Now())
+	_, ok := s.items[id]
+	return ok
+}
+
+// Len returns the number of IDs currently remembered
+func (s *Store) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.order.Len()
+}
+
+// Close closes the backing file, if any
+func (s *Store) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.file == nil {
+		return nil
+	}
+	err := s.file.Close()
+	s.file = nil
+	return err
+}
+
+func (s *Store) insert(id string, seenAt time.Time, pending bool) {
+	if s.window > 0 && time.Since(seenAt) > s.window {
+		return
+	}
+	if elem, ok := s.items[id]; ok {
+		s.order.Remove(elem)
+	}
+	s.items[id] = s.order.PushBack(&entry{id: id, seenAt: seenAt, pending: pending})
+
+	for s.order.Len() > s.capacity {
+		s.evict(s.order.Front())
+	}
+}
+
+// expire drops entries that fell out of the time window
+func (s *Store) expire(now time.Time) {
+	if s.window <= 0 {
+		return
+	}
+	for elem := s.order.Front(); elem != nil; elem = s.order.Front() {
+		if now.Sub(elem.Value.(*entry).seenAt) <= s.window {
+			return
+		}
+		s.evict(elem)
+	}
+}
+
+func (s *Store) evict(elem *list.Element) {
+	s.order.Remove(elem)
+	delete(s.items, elem.Value.(*entry).id)
+}
+
+// compact rewrites the backing file with the handled entries currently held in memory
+func (s *Store) compact() error {
+	if s.file != nil {
+		s.file.Close()
+		s.file = nil
+	}
+
+	var b strings.Builder
+	for elem := s.order.Front(); elem != nil; elem = elem.Next() {
+		e := elem.Value.(*entry)
+		if e.pending {
+			continue
+		}
+		fmt.Fprintf(&b, "%d %s\n", e.seenAt.Unix(), e.id)
+	}
+
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, []byte(b.String()), 0600); err != nil {
+		return fmt.Errorf("failed to write dedupe file: %w", err)
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		return fmt.Errorf("failed to write dedupe file: %w", err)
+	}
+
+	f, err := os.OpenFile(s.path, os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open dedupe file: %w", err)
+	}
+	s.file = f
+	s.appended = 0
+	return nil
+}
@@ -0,0 +1,218 @@
+package dedupe
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// add calls Add and fails the test if the backing file could not be written
+func add(t *testing.T, s *Store, id string) bool {
+	t.Helper()
+	added, err := s.Add(id)
+	if err != nil {
+		t.Fatalf("Add(%s) error = %v", id, err)
+	}
+	return added
+}
+
+func TestAdd(t *testing.T) {
+	s := New(10, 0)
+	if !add(t, s, "evt_1") {
+		t.Fatal("Add of a new ID returned false")
+	}
+	if add(t, s, "evt_1") {
+		t.Fatal("Add of a known ID returned true")
+	}
+	if !s.Seen("evt_1") || s.Seen("evt_2") {
+		t.Fatal("Seen does not match what was added")
+	}
+}
+
+func TestEvictBySize(t *testing.T) {
+	s := New(3, 0)
+	for i := 1; i <= 5; i++ {
+		add(t, s, fmt.Sprintf("evt_%d", i))
+	}
+
+	if got := s.Len(); got != 3 {
+		t.Fatalf("Len() = %d, want 3", got)
+	}
+	for i := 1; i <= 5; i++ {
+		id := fmt.Sprintf("evt_%d", i)
+		if want := i > 2; s.Seen(id) != want {
+			t.Errorf("Seen(%s) = %v, want %v", id, !want, want)
+		}
+	}
+}
+
+func TestEvictByWindow(t *testing.T) {
+	s := New(10, 50*time.Millisecond)
+	add(t, s, "evt_old")
+	time.Sleep(100 * time.Millisecond)
+	add(t, s, "evt_new")
+
+	if s.Seen("evt_old") {
+		t.Error("ID older than the window is still seen")
+	}
+	if !s.Seen("evt_new") {
+		t.Error("ID within the window is not seen")
+	}
+	if got := s.Len(); got != 1 {
+		t.Errorf("Len() = %d, want 1", got)
+	}
+	// An expired ID counts as new again
+	if !add(t, s, "evt_old") {
+		t.Error("Add of an expired ID returned false")
+	}
+}
+
+func TestMarkIsPending(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dedupe.log")
+	s, err := Open(path, 10, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !s.Mark("evt_pending") || !s.Mark("evt_handled") {
+		t.Fatal("Mark of a new ID returned false")
+	}
+	if s.Mark("evt_pending") {
+		t.Fatal("Mark of a known ID returned true")
+	}
+	if !s.Seen("evt_pending") {
+		t.Fatal("pending ID is not seen")
+	}
+	// Confirming a pending ID writes it once
+	if !add(t, s, "evt_handled") {
+		t.Fatal("Add of a pending ID returned false")
+	}
+	if add(t, s, "evt_handled") {
+		t.Fatal("Add of a handled ID returned true")
+	}
+	s.Close()
+
+	s, err = Open(path, 10, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	if s.Seen("evt_pending") {
+		t.Error("pending ID survived a restart")
+	}
+	if !s.Seen("evt_handled") {
+		t.Error("handled ID did not survive a restart")
+	}
+}
+
+func TestReload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "dedupe.log")
+	s, err := Open(path, 10, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	add(t, s, "evt_1")
+	add(t, s, "evt_2")
+	s.Close()
+
+	s, err = Open(path, 10, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	for _, id := range []string{"evt_1", "evt_2"} {
+		if !s.Seen(id) {
+			t.Errorf("%s was not reloaded", id)
+		}
+	}
+	if add(t, s, "evt_1") {
+		t.Error("Add of a reloaded ID returned true")
+	}
+}
+
+func TestReloadSkipsExpiredAndMalformedLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dedupe.log")
+	now := time.Now().Unix()
+	lines := []string{
+		fmt.Sprintf("%d evt_old", now-7200),
+		fmt.Sprintf("%d evt_new", now),
+		"not-a-timestamp evt_bad",
+		"no-separator",
+		fmt.Sprintf("%d ", now),
+	}
+	if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := Open(path, 10, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	if got := s.Len(); got != 1 || !s.Seen("evt_new") {
+		t.Fatalf("loaded %d IDs, want only evt_new", got)
+	}
+
+	// Open compacts the file down to the live entries
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := string(data), fmt.Sprintf("%d evt_new\n", now); got != want {
+		t.Errorf("file after Open = %q, want %q", got, want)
+	}
+}
+
+func TestCompaction(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dedupe.log")
+	const capacity = 5
+	s, err := Open(path, capacity, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	for i := 0; i < 4*capacity; i++ {
+		add(t, s, fmt.Sprintf("evt_%d", i))
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The log is rewritten with the live entries once more than twice the capacity
+	// was appended to it
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) > 3*capacity {
+		t.Fatalf("file has %d lines, want at most %d", len(lines), 3*capacity)
+	}
+	last := fmt.Sprintf("evt_%d", 4*capacity-1)
+	if !strings.HasSuffix(lines[len(lines)-1], " "+last) {
+		t.Errorf("last line = %q, want it to record %s", lines[len(lines)-1], last)
+	}
+	for _, line := range lines {
+		if strings.HasSuffix(line, " evt_0") {
+			t.Error("evicted ID evt_0 is still in the file")
+		}
+	}
+}
+
+func TestAddReturnsWriteErrors(t *testing.T) {
+	s, err := Open(filepath.Join(t.TempDir(), "dedupe.log"), 10, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	// Writes to a closed file fail
+	s.file.Close()
+
+	added, err := s.Add("evt_1")
+	if err == nil || !strings.Contains(err.Error(), "evt_1") {
+		t.Errorf("Add() error = %v, want a write error naming evt_1", err)
+	}
+	if !added || !s.Seen("evt_1") {
+		t.Error("ID was not recorded in memory after a write error")
+	}
+}
@@ -0,0 +1,157 @@
+package dlq
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/volleyhq/volley-cli/internal/api"
+	"github.com/volleyhq/volley-cli/internal/config"
+)
+
+// Entry is an event that could not be delivered to a local target,
+// kept with everything needed to redeliver it later
+type Entry struct {
+	ID        string    `json:"id"`
+	Event     api.Event `json:"event"`
+	TargetURL string    `json:"target_url"`
+	// Whether the event was forwarded with its original method, path and query string
+	PreserveRequest bool `json:"preserve_request,omitempty"`
+	// Header rules the event was forwarded with, in the --add-header, --remove-header
+	// and --rename-header syntax
+	AddHeaders    []string `json:"add_headers,omitempty"`
+	RemoveHeaders []string `json:"remove_headers,omitempty"`
+	RenameHeaders []string `json:"rename_headers,omitempty"`
+	// --transform command that failed on the event, run again before redelivering
+	Transform string `json:"transform,omitempty"`
+	// --resign provider and header the event was forwarded with. The secret is not
+	// stored and has to be given again to redeliver.
+	ResignProvider string    `json:"resign_provider,omitempty"`
+	ResignHeader   string    `json:"resign_header,omitempty"`
+	StatusCode     int       `json:"status_code,omitempty"`
+	Error          string    `json:"error"`
+	Attempts       int       `json:"attempts"`
+	FailedAt       time.Time `json:"failed_at"`
+}
+
+// Store keeps dead-letter entries as one JSON file per entry in a directory
+type Store struct {
+	dir string
+}
+
+// DefaultDir returns the dead-letter directory inside the config directory
+func DefaultDir() string {
+	return filepath.Join(config.Dir(), "dlq")
+}
+
+// Open returns a store backed by dir, creating the directory if needed
+func Open(dir string) (*Store, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create dead-letter directory: %w", err)
+	}
+	return &Store{dir: dir}, nil
+}
+
+// EntryID returns the ID used for an event/target pair, so repeated failures
+// of the same delivery update one entry instead of piling up
+func EntryID(eventID, targetURL string) string {
+	sum := sha256.Sum256([]byte(targetURL))
+	return eventID + "-" + hex.EncodeToString(sum[:4])
+}
+
+// Add records a failed delivery described by entry, filling in its ID, attempt count
+// and failure time. If the same event already failed for the same target, the existing
+// entry is replaced and its attempt count incremented.
+func (s *Store) Add(entry *Entry) error {
+	entry.ID = EntryID(entry.Event.EventID, entry.TargetURL)
+	entry.Attempts = 1
+	entry.FailedAt = time.Now()
+	if existing, err := s.Get(entry.ID); err == nil {
+		entry.Attempts = existing.Attempts + 1
+	}
+	return s.write(entry)
+}
+
+// Update stores a modified entry
+func (s *Store) Update(entry *Entry) error {
+	return s.write(entry)
+}
+
+// Get returns the entry with the given ID
+func (s *Store) Get(id string) (*Entry, error) {
+	data, err := os.ReadFile(s.path(id))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("dead-letter entry '%s' not found", id)
+		}
+		return nil, fmt.Errorf("failed to read dead-letter entry: %w", err)
+	}
+
+	var entry Entry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil, fmt.Errorf("failed to parse dead-letter entry '%s': %w", id, err)
+	}
+	return &entry, nil
+}
+
+// List returns all entries, oldest failure first
+func (s *Store) List() ([]Entry, error) {
+	files, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read dead-letter directory: %w", err)
+	}
+
+	var entries []Entry
+	for _, f := range files {
+		if f.IsDir() || !strings.HasSuffix(f.Name(), ".json") {
+			continue
+		}
+		entry, err := s.Get(strings.TrimSuffix(f.Name(), ".json"))
+		if err != nil {
+			// Skip unreadable files rather than hiding every other entry
+			continue
+		}
+		entries = append(entries, *entry)
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].FailedAt.Before(entries[j].FailedAt)
+	})
+	return entries, nil
+}
+
+// Remove deletes an entry, typically after it was redelivered successfully
+func (s *Store) Remove(id string) error {
+	if err := os.Remove(s.path(id)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove dead-letter entry: %w", err)
+	}
+	return nil
+}
+
+func (s *Store) write(entry *Entry) error {
+	data, err := json.MarshalIndent(entry, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal dead-letter entry: %w", err)
+	}
+
+	path := s.path(entry.ID)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("failed to write dead-letter entry: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("failed to write dead-letter entry: %w", err)
+	}
+	return nil
+}
+
+func (s *Store) path(id string) string {
+	// IDs come from the command line too; never let them escape the directory
+	return filepath.Join(s.dir, filepath.Base(id)+".json")
+}
@@ -0,0 +1,209 @@
+// Package filter decides which events are forwarded, based on their headers and on
+// fields of their JSON body.
+package filter
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Filter is one condition an event must meet to be forwarded
+type Filter interface {
+	// Match reports whether an event with these headers and raw body meets the condition
+	Match(headers map[string][]string, body []byte) bool
+	String() string
+}
+
+// headerFilter matches "Name=value" or "Name!=value" against a request header
+type headerFilter struct {
+	name   string
+	value  string
+	negate bool
+}
+
+// ParseHeader parses a header filter of the form "Name=value" or "Name!=value".
+// Names are case-insensitive, values are compared exactly.
+func ParseHeader(expr string) (Filter, error) {
+	name, value, ok := strings.Cut(expr, "=")
+	if !ok {
+		return nil, fmt.Errorf("invalid header filter %q: expected Name=value or Name!=value", expr)
+	}
+	f := &headerFilter{value: strings.TrimSpace(value)}
+	if n, found := strings.CutSuffix(name, "!"); found {
+		name, f.negate = n, true
+	}
+	f.name = http.CanonicalHeaderKey(strings.TrimSpace(name))
+	if f.name == "" {
+		return nil, fmt.Errorf("invalid header filter %q: missing header name", expr)
+	}
+	return f, nil
+}
+
+func (f *headerFilter) Match(headers map[string][]string, body []byte) bool {
+	found := false
+	for key, values := range headers {
+		if !strings.EqualFold(key, f.name) {
+			continue
+		}
+		for _, v := range values {
+			if v == f.value {
+				found = true
+			}
+		}
+	}
+	return found != f.negate
+}
+
+func (f *headerFilter) String() string {
+	if f.negate {
+		return fmt.Sprintf("header %s != %s", f.name, f.value)
+	}
+	return fmt.Sprintf("header %s = %s", f.name, f.value)
+}
+
+// bodyFilter matches a field of the JSON body, addressed by a dot separated path
+type bodyFilter struct {
+	path   []string
+	op     string // "=", "!=", "in" or "not in"
+	values []string
+}
+
+// exprPattern splits a body filter into its path, operator and value. The operator is
+// only looked for right after the path, so values may contain "=" or "!=" themselves.
+var exprPattern = regexp.MustCompile(`^\s*([^\s=!]+)(?:\s*(!=|=)|\s+(in|not\s+in)\b)(.*)$`)
+
+// Parse parses a body filter. Supported forms are:
+//
+//	type = invoice.paid
+//	type != invoice.paid
+//	type in [invoice.paid, invoice.failed]
+//	type not in [invoice.paid, invoice.failed]
+//
+// Paths address nested fields and array elements with dots (data.object.items.0.id).
+// Values may be quoted; a body that is not JSON or lacks the field never equals a value.
+func Parse(expr string) (Filter, error) {
+	invalid := func(reason string) error {
+		return fmt.Errorf("invalid filter %q: %s", expr, reason)
+	}
+
+	m := exprPattern.FindStringSubmatch(expr)
+	if m == nil {
+		return nil, invalid("expected <path> = <value>, <path> != <value>, <path> in [...] or <path> not in [...]")
+	}
+	path, op, rest := m[1], m[2], m[4]
+	if op == "" {
+		op = strings.Join(strings.Fields(m[3]), " ")
+	}
+	f := &bodyFilter{path: strings.Split(path, "."), op: op}
+
+	rest = strings.TrimSpace(rest)
+	if op == "in" || op == "not in" {
+		list, ok := strings.CutPrefix(rest, "[")
+		if list, ok = strings.CutSuffix(list, "]"); !ok {
+			return nil, invalid("expected a list like [a, b]")
+		}
+		for _, v := range strings.Split(list, ",") {
+			if v = unquote(strings.TrimSpace(v)); v != "" {
+				f.values = append(f.values, v)
+			}
+		}
+		if len(f.values) == 0 {
+			return nil, invalid("empty list")
+		}
+	} else {
+		f.values = []string{unquote(rest)}
+	}
+	return f, nil
+}
+
+// unquote strips matching single or double quotes around a value
+func unquote(s string) string {
+	if len(s) >= 2 && (s[0] == '"' || s[0] == '\'') && s[len(s)-1] == s[0] {
+		return s[1 : len(s)-1]
+	}
+	return s
+}
+
+func (f *bodyFilter) Match(headers map[string][]string, body []byte) bool {
+	value, ok := lookup(body, f.path)
+	found := false
+	if ok {
+		for _, v := range f.values {
+			if v == value {
+				found = true
+				break
+			}
+		}
+	}
+	if f.op == "!=" || f.op == "not in" {
+		return !found
+	}
+	return found
+}
+
+func (f *bodyFilter) String() string {
+	path := strings.Join(f.path, ".")
+	if f.op == "in" || f.op == "not in" {
+		return fmt.Sprintf("%s %s [%s]", path, f.op, strings.Join(f.values, ", "))
+	}
+	return fmt.Sprintf("%s %s %s", path, f.op, f.values[0])
+}
+
+// lookup returns the field at path in a JSON document as text: strings unquoted,
+// numbers as written, booleans and null as their literals, objects and arrays as JSON
+func lookup(body []byte, path []string) (string, bool) {
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	var doc interface{}
+	if err := dec.Decode(&doc); err != nil {
+		return "", false
+	}
+
+	for _, key := range path {
+		switch node := doc.(type) {
+		case map[string]interface{}:
+			v, ok := node[key]
+			if !ok {
+				return "", false
+			}
+			doc = v
+		case []interface{}:
+			i, err := strconv.Atoi(key)
+			if err != nil || i < 0 || i >= len(node) {
+				return "", false
+			}
+			doc = node[i]
+		default:
+			return "", false
+		}
+	}
+
+	switch v := doc.(type) {
+	case string:
+		return v, true
+	case json.Number:
+		return v.String(), true
+	case nil:
+		return "null", true
+	case bool:
+		return strconv.FormatBool(v), true
+	default:
+		b, _ := json.Marshal(v)
+		return string(b), true
+	}
+}
+
+// First returns the first filter the event does not meet, or nil if it meets all of them
+func First(filters []Filter, headers map[string][]string, body []byte) Filter {
+	for _, f := range filters {
+		if !f.Match(headers, body) {
+			return f
+		}
+	}
+	return nil
+}
@@ -0,0 +1,147 @@
+package filter
+
+import "testing"
+
+const invoiceBody = `{
+	"type": "invoice.paid",
+	"msg": "a != b",
+	"livemode": false,
+	"amount": 1200,
+	"customer": null,
+	"data": {"object": {"id": "in_1", "items": [{"id": "ii_1"}, {"id": "ii_2"}], "tags": ["a", "b"]}}
+}`
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		expr   string
+		body   string
+		want   bool
+		string string
+	}{
+		// =
+		{expr: "type = invoice.paid", body: invoiceBody, want: true, string: "type = invoice.paid"},
+		{expr: "type=invoice.paid", body: invoiceBody, want: true},
+		{expr: "type = invoice.failed", body: invoiceBody, want: false},
+		{expr: "amount = 1200", body: invoiceBody, want: true},
+		{expr: "livemode = false", body: invoiceBody, want: true},
+		{expr: "customer = null", body: invoiceBody, want: true},
+		{expr: "missing = x", body: invoiceBody, want: false},
+		// Operators inside the value belong to the value
+		{expr: "msg = a != b", body: invoiceBody, want: true, string: "msg = a != b"},
+		{expr: "msg != a = b", body: invoiceBody, want: true, string: "msg != a = b"},
+		{expr: "msg = 'a != b'", body: invoiceBody, want: true},
+		// !=
+		{expr: "type != invoice.failed", body: invoiceBody, want: true, string: "type != invoice.failed"},
+		{expr: "type!=invoice.paid", body: invoiceBody, want: false},
+		{expr: "missing != x", body: invoiceBody, want: true},
+		// in and not in
+		{expr: "type in [invoice.paid, invoice.failed]", body: invoiceBody, want: true, string: "type in [invoice.paid, invoice.failed]"},
+		{expr: "type in [invoice.created]", body: invoiceBody, want: false},
+		{expr: "type not in [invoice.paid, invoice.failed]", body: invoiceBody, want: false, string: "type not in [invoice.paid, invoice.failed]"},
+		{expr: "type  not   in [invoice.created]", body: invoiceBody, want: true, string: "type not in [invoice.created]"},
+		{expr: "type in ['invoice.paid', \"invoice.failed\"]", body: invoiceBody, want: true},
+		{expr: "missing not in [x]", body: invoiceBody, want: true},
+		// Quoted values
+		{expr: `type = "invoice.paid"`, body: invoiceBody, want: true, string: "type = invoice.paid"},
+		{expr: "type = 'invoice.paid'", body: invoiceBody, want: true},
+		{expr: `type = "invoice.paid'`, body: invoiceBody, want: false},
+		// Nested paths and array indexes
+		{expr: "data.object.id = in_1", body: invoiceBody, want: true},
+		{expr: "data.object.items.1.id = ii_2", body: invoiceBody, want: true},
+		{expr: "data.object.items.2.id = ii_3", body: invoiceBody, want: false},
+		{expr: "data.object.items.-1.id = ii_2", body: invoiceBody, want: false},
+		{expr: "data.object.items.x.id = ii_2", body: invoiceBody, want: false},
+		{expr: `data.object.tags = ["a","b"]`, body: invoiceBody, want: true},
+		{expr: "data.object.tags.0 in [a]", body: invoiceBody, want: true},
+		{expr: "type.name = x", body: invoiceBody, want: false},
+		// Bodies that are not JSON never equal a value
+		{expr: "type = invoice.paid", body: "type=invoice.paid", want: false},
+		{expr: "type != invoice.paid", body: "type=invoice.paid", want: true},
+		{expr: "type in [invoice.paid]", body: "", want: false},
+		{expr: "type not in [invoice.paid]", body: "<xml/>", want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			f, err := Parse(tt.expr)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if got := f.Match(nil, []byte(tt.body)); got != tt.want {
+				t.Errorf("Match() = %v, want %v", got, tt.want)
+			}
+			if tt.string != "" && f.String() != tt.string {
+				t.Errorf("String() = %q, want %q", f.String(), tt.string)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, expr := range []string{
+		"",
+		"type",
+		"= invoice.paid",
+		"!= invoice.paid",
+		"data object = x",
+		"type in invoice.paid",
+		"type in [invoice.paid",
+		"type in []",
+		"type not in [ , ]",
+	} {
+		if _, err := Parse(expr); err == nil {
+			t.Errorf("Parse(%q) succeeded, want an error", expr)
+		}
+	}
+}
+
+func TestParseHeader(t *testing.T) {
+	headers := map[string][]string{
+		"x-github-event": {"push"},
+		"X-Tags":         {"a", "b=c"},
+	}
+	tests := []struct {
+		expr   string
+		want   bool
+		string string
+	}{
+		{expr: "X-GitHub-Event=push", want: true, string: "header X-Github-Event = push"},
+		{expr: "x-github-event = push", want: true},
+		{expr: "X-GitHub-Event=ping", want: false},
+		{expr: "X-GitHub-Event!=ping", want: true, string: "header X-Github-Event != ping"},
+		{expr: "X-GitHub-Event!=push", want: false},
+		{expr: "X-Tags=b=c", want: true},
+		{expr: "X-Missing=x", want: false},
+		{expr: "X-Missing!=x", want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			f, err := ParseHeader(tt.expr)
+			if err != nil {
+				t.Fatalf("ParseHeader() error = %v", err)
+			}
+			if got := f.Match(headers, nil); got != tt.want {
+				t.Errorf("Match() = %v, want %v", got, tt.want)
+			}
+			if tt.string != "" && f.String() != tt.string {
+				t.Errorf("String() = %q, want %q", f.String(), tt.string)
+			}
+		})
+	}
+
+	for _, expr := range []string{"X-GitHub-Event", "=push", "!=push"} {
+		if _, err := ParseHeader(expr); err == nil {
+			t.Errorf("ParseHeader(%q) succeeded, want an error", expr)
+		}
+	}
+}
+
+func TestFirst(t *testing.T) {
+	pass, _ := Parse("type = invoice.paid")
+	fail, _ := Parse("amount = 1")
+	if f := First([]Filter{pass}, nil, []byte(invoiceBody)); f != nil {
+		t.Errorf("First() = %v, want nil", f)
+	}
+	if f := First([]Filter{pass, fail}, nil, []byte(invoiceBody)); f != fail {
+		t.Errorf("First() = %v, want %v", f, fail)
+	}
+}
@@ -0,0 +1,136 @@
+package metrics
+
+import (
+	"math"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func writeText(t *testing.T, r *Registry) string {
+	t.Helper()
+	var b strings.Builder
+	if err := r.WriteText(&b); err != nil {
+		t.Fatal(err)
+	}
+	return b.String()
+}
+
+func TestCounterAndGauge(t *testing.T) {
+	r := NewRegistry()
+	c := r.Counter("events_total", "Events seen.", "source")
+	g := r.Gauge("interval_seconds", "Current interval.")
+	c.Inc("b")
+	c.Add(2.5, "a")
+	c.Inc("a")
+	g.Set(2)
+	g.Set(5)
+
+	want := `# HELP events_total Events seen.
+# TYPE events_total counter
+events_total{source="a"} 3.5
+events_total{source="b"} 1
+# HELP interval_seconds Current interval.
+# TYPE interval_seconds gauge
+interval_seconds 5
+`
+	if got := writeText(t, r); got != want {
+		t.Errorf("WriteText() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestHistogram(t *testing.T) {
+	r := NewRegistry()
+	// Buckets are sorted on registration
+	h := r.Histogram("latency_seconds", "Request latency.", []float64{1, 0.1, 0.5}, "target")
+	for _, v := range []float64{0.05, 0.1, 0.3, 0.7, 2} {
+		h.Observe(v, "x")
+	}
+
+	// Buckets are cumulative and end with +Inf, which equals _count
+	want := `# HELP latency_seconds Request latency.
+# TYPE latency_seconds histogram
+latency_seconds_bucket{target="x",le="0.1"} 2
+latency_seconds_bucket{target="x",le="0.5"} 3
+latency_seconds_bucket{target="x",le="1"} 4
+latency_seconds_bucket{target="x",le="+Inf"} 5
+latency_seconds_sum{target="x"} 3.15
+latency_seconds_count{target="x"} 5
+`
+	if got := writeText(t, r); got != want {
+		t.Errorf("WriteText() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestHistogramWithoutLabels(t *testing.T) {
+	r := NewRegistry()
+	h := r.Histogram("size_bytes", "Body size.", []float64{10})
+	h.Observe(20)
+
+	want := `# HELP size_bytes Body size.
+# TYPE size_bytes histogram
+size_bytes_bucket{le="10"} 0
+size_bytes_bucket{le="+Inf"} 1
+size_bytes_sum 20
+size_bytes_count 1
+`
+	if got := writeText(t, r); got != want {
+		t.Errorf("WriteText() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestEscaping(t *testing.T) {
+	r := NewRegistry()
+	c := r.Counter("escaped_total", "Help with a \\ backslash,\na newline and \"quotes\".", "target")
+	c.Inc("C:\\hook \"main\"\nnext")
+
+	want := `# HELP escaped_total Help with a \\ backslash,\na newline and "quotes".
+# TYPE escaped_total counter
+escaped_total{target="C:\\hook \"main\"\nnext"} 1
+`
+	if got := writeText(t, r); got != want {
+		t.Errorf("WriteText() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestFormatValue(t *testing.T) {
+	tests := []struct {
+		v    float64
+		want string
+	}{
+		{0, "0"},
+		{1, "1"},
+		{0.25, "0.25"},
+		{1e21, "1e+21"},
+		{math.Inf(1), "+Inf"},
+		{math.Inf(-1), "-Inf"},
+	}
+	for _, tt := range tests {
+		if got := formatValue(tt.v); got != tt.want {
+			t.Errorf("formatValue(%v) = %q, want %q", tt.v, got, tt.want)
+		}
+	}
+}
+
+func TestWrongLabelCountPanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("Inc() with a missing label value did not panic")
+		}
+	}()
+	NewRegistry().Counter("events_total", "Events.", "source", "target").Inc("a")
+}
+
+func TestHandler(t *testing.T) {
+	r := NewRegistry()
+	r.Counter("events_total", "Events.").Inc()
+
+	rec := httptest.NewRecorder()
+	r.Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain; version=0.0.4") {
+		t.Errorf("Content-Type = %q, want the Prometheus text format", ct)
+	}
+	if !strings.Contains(rec.Body.String(), "\nevents_total 1\n") {
+		t.Errorf("body = %q, want the counter", rec.Body.String())
+	}
+}
@@ -0,0 +1,374 @@
+// Package signature verifies and computes webhook signatures of well-known providers
+// over the raw request body, so secret mismatches can be spotted before a request
+// reaches a handler and forwarded requests can be re-signed with a local secret.
+package signature
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultHeader is the header checked by the generic hmac-sha256 scheme
+const DefaultHeader = "X-Signature"
+
+var (
+	// ErrMissingSignature is returned when the request carries no signature for the scheme
+	ErrMissingSignature = errors.New("signature header missing")
+	// ErrInvalidSignature is returned when no signature matches the secret
+	ErrInvalidSignature = errors.New("signature does not match secret")
+)
+
+// verifyFunc checks the signature in headers against body signed with secret
+type verifyFunc func(v *Verifier, headers http.Header, body []byte) error
+
+// signFunc replaces the signature headers with ones computed over body at time now
+type signFunc func(s *Signer, headers http.Header, body []byte, now time.Time)
+
+type scheme struct {
+	verify verifyFunc
+	sign   signFunc
+}
+
+var schemes = map[string]scheme{
+	"stripe":      {verifyStripe, signStripe},
+	"github":      {verifyGitHub, signGitHub},
+	"paddle":      {verifyPaddle, signPaddle},
+	"shopify":     {verifyShopify, signShopify},
+	"svix":        {verifySvix, signSvix},
+	"slack":       {verifySlack, signSlack},
+	"hmac-sha256": {verifyHMAC, signHMAC},
+}
+
+// Providers returns the names of the supported schemes, sorted
+func Providers() []string {
+	names := make([]string, 0, len(schemes))
+	for name := range schemes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Verifier checks signatures of one provider with one secret. Timestamps that are part of
+// a signature are not compared with the current time, since events may be replayed.
+type Verifier struct {
+	Provider string
+	secret   string
+	header   string
+	verify   verifyFunc
+}
+
+// NewVerifier returns a verifier for provider. header is only used by the generic
+// hmac-sha256 scheme and defaults to DefaultHeader.
+func NewVerifier(provider, secret, header string) (*Verifier, error) {
+	sch, header, err := lookup(provider, secret, header)
+	if err != nil {
+		return nil, err
+	}
+	return &Verifier{Provider: provider, secret: secret, header: header, verify: sch.verify}, nil
+}
+
+// lookup validates a provider and secret and resolves the default header
+func lookup(provider, secret, header string) (scheme, string, error) {
+	sch, ok := schemes[provider]
+	if !ok {
+		return scheme{}, "", fmt.Errorf("unknown signature provider %q: must be one of %s", provider, strings.Join(Providers(), ", "))
+	}
+	if secret == "" {
+		return scheme{}, "", errors.New("a signing secret is required")
+	}
+	if provider == "svix" {
+		if _, err := svixSecret(secret); err != nil {
+			return scheme{}, "", err
+		}
+	}
+	if header == "" {
+		header = DefaultHeader
+	}
+	return sch, header, nil
+}
+
+// Verify checks the signature of a request with the given headers and exact raw body
+func (v *Verifier) Verify(headers map[string][]string, body []byte) error {
+	// Canonicalize so lookups do not depend on how the headers were stored
+	h := make(http.Header, len(headers))
+	for key, values := range headers {
+		for _, value := range values {
+			h.Add(key, value)
+		}
+	}
+	return v.verify(v, h, body)
+}
+
+// Signer computes signatures of one provider with one secret
+type Signer struct {
+	Provider string
+	// Header the generic hmac-sha256 scheme writes; ignored by the other providers
+	Header string
+	secret string
+	sign   signFunc
+}
+
+// NewSigner returns a signer for provider. header is only used by the generic
+// hmac-sha256 scheme and defaults to DefaultHeader.
+func NewSigner(provider, secret, header string) (*Signer, error) {
+	sch, header, err := lookup(provider, secret, header)
+	if err != nil {
+		return nil, err
+	}
+	return &Signer{Provider: provider, Header: header, secret: secret, sign: sch.sign}, nil
+}
+
+// Sign replaces the provider's signature headers in h with a signature over the exact
+// raw body. Schemes that sign a timestamp get a fresh one taken from now.
+func (s *Signer) Sign(h http.Header, body []byte, now time.Time) {
+	s.sign(s, h, body, now)
+}
+
+func computeHMAC(secret []byte, parts ...string) []byte {
+	mac := hmac.New(sha256.New, secret)
+	for _, part := range parts {
+		mac.Write([]byte(part))
+	}
+	return mac.Sum(nil)
+}
+
+// matchHex reports whether any of the hex encoded signatures equals expected
+func matchHex(expected []byte, signatures ...string) bool {
+	for _, sig := range signatures {
+		decoded, err := hex.DecodeString(strings.TrimSpace(sig))
+		if err == nil && hmac.Equal(decoded, expected) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchBase64 reports whether any of the base64 encoded signatures equals expected
+func matchBase64(expected []byte, signatures ...string) bool {
+	for _, sig := range signatures {
+		decoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(sig))
+		if err == nil && hmac.Equal(decoded, expected) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseParams splits headers like "t=123,v1=abc,v1=def" into their values by key
+func parseParams(header, sep string) map[string][]string {
+	params := make(map[string][]string)
+	for _, part := range strings.Split(header, sep) {
+		key, value, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if ok {
+			params[key] = append(params[key], value)
+		}
+	}
+	return params
+}
+
+// verifyStripe checks Stripe-Signature: t=<ts>,v1=<hex hmac of "ts.body">
+func verifyStripe(v *Verifier, h http.Header, body []byte) error {
+	header := h.Get("Stripe-Signature")
+	if header == "" {
+		return ErrMissingSignature
+	}
+	params := parseParams(header, ",")
+	if len(params["t"]) == 0 || len(params["v1"]) == 0 {
+		return fmt.Errorf("malformed Stripe-Signature header")
+	}
+	expected := computeHMAC([]byte(v.secret), params["t"][0], ".", string(body))
+	if !matchHex(expected, params["v1"]...) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// verifyGitHub checks X-Hub-Signature-256: sha256=<hex hmac of body>
+func verifyGitHub(v *Verifier, h http.Header, body []byte) error {
+	header := h.Get("X-Hub-Signature-256")
+	if header == "" {
+		return ErrMissingSignature
+	}
+	sig, ok := strings.CutPrefix(header, "sha256=")
+	if !ok {
+		return fmt.Errorf("malformed X-Hub-Signature-256 header")
+	}
+	if !matchHex(computeHMAC([]byte(v.secret), string(body)), sig) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// verifyPaddle checks Paddle-Signature: ts=<ts>;h1=<hex hmac of "ts:body">
+func verifyPaddle(v *Verifier, h http.Header, body []byte) error {
+	header := h.Get("Paddle-Signature")
+	if header == "" {
+		return ErrMissingSignature
+	}
+	params := parseParams(header, ";")
+	if len(params["ts"]) == 0 || len(params["h1"]) == 0 {
+		return fmt.Errorf("malformed Paddle-Signature header")
+	}
+	expected := computeHMAC([]byte(v.secret), params["ts"][0], ":", string(body))
+	if !matchHex(expected, params["h1"]...) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// verifyShopify checks X-Shopify-Hmac-Sha256: <base64 hmac of body>
+func verifyShopify(v *Verifier, h http.Header, body []byte) error {
+	header := h.Get("X-Shopify-Hmac-Sha256")
+	if header == "" {
+		return ErrMissingSignature
+	}
+	if !matchBase64(computeHMAC([]byte(v.secret), string(body)), header) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// verifySvix checks svix-signature: "v1,<base64 hmac of id.ts.body>" (space separated list).
+// The Standard Webhooks webhook-* headers use the same scheme.
+func verifySvix(v *Verifier, h http.Header, body []byte) error {
+	prefix := "Svix-"
+	if h.Get("Svix-Signature") == "" {
+		prefix = "Webhook-"
+	}
+	id, ts, header := h.Get(prefix+"Id"), h.Get(prefix+"Timestamp"), h.Get(prefix+"Signature")
+	if header == "" {
+		return ErrMissingSignature
+	}
+	if id == "" || ts == "" {
+		return fmt.Errorf("missing %sid or %stimestamp header", strings.ToLower(prefix), strings.ToLower(prefix))
+	}
+
+	secret, err := svixSecret(v.secret)
+	if err != nil {
+		return err
+	}
+	expected := computeHMAC(secret, id, ".", ts, ".", string(body))
+
+	var sigs []string
+	for _, part := range strings.Fields(header) {
+		if sig, ok := strings.CutPrefix(part, "v1,"); ok {
+			sigs = append(sigs, sig)
+		}
+	}
+	if !matchBase64(expected, sigs...) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// svixSecret decodes a "whsec_<base64>" secret to its key bytes
+func svixSecret(secret string) ([]byte, error) {
+	key, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(secret, "whsec_"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid svix secret: expected whsec_<base64>")
+	}
+	return key, nil
+}
+
+// verifySlack checks X-Slack-Signature: v0=<hex hmac of "v0:ts:body">
+func verifySlack(v *Verifier, h http.Header, body []byte) error {
+	header := h.Get("X-Slack-Signature")
+	if header == "" {
+		return ErrMissingSignature
+	}
+	ts := h.Get("X-Slack-Request-Timestamp")
+	if ts == "" {
+		return fmt.Errorf("missing X-Slack-Request-Timestamp header")
+	}
+	sig, ok := strings.CutPrefix(header, "v0=")
+	if !ok {
+		return fmt.Errorf("malformed X-Slack-Signature header")
+	}
+	if !matchHex(computeHMAC([]byte(v.secret), "v0:", ts, ":", string(body)), sig) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// verifyHMAC checks a plain HMAC-SHA256 of the body in the configured header, hex or
+// base64 encoded, with an optional "sha256=" prefix
+func verifyHMAC(v *Verifier, h http.Header, body []byte) error {
+	header := h.Get(v.header)
+	if header == "" {
+		return ErrMissingSignature
+	}
+	sig := strings.TrimPrefix(header, "sha256=")
+	expected := computeHMAC([]byte(v.secret), string(body))
+	if !matchHex(expected, sig) && !matchBase64(expected, sig) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+func signStripe(s *Signer, h http.Header, body []byte, now time.Time) {
+	ts := strconv.FormatInt(now.Unix(), 10)
+	sig := hex.EncodeToString(computeHMAC([]byte(s.secret), ts, ".", string(body)))
+	h.Set("Stripe-Signature", "t="+ts+",v1="+sig)
+}
+
+func signGitHub(s *Signer, h http.Header, body []byte, now time.Time) {
+	// The legacy SHA-1 signature would no longer match, drop it rather than forward it stale
+	h.Del("X-Hub-Signature")
+	h.Set("X-Hub-Signature-256", "sha256="+hex.EncodeToString(computeHMAC([]byte(s.secret), string(body))))
+}
+
+func signPaddle(s *Signer, h http.Header, body []byte, now time.Time) {
+	ts := strconv.FormatInt(now.Unix(), 10)
+	sig := hex.EncodeToString(computeHMAC([]byte(s.secret), ts, ":", string(body)))
+	h.Set("Paddle-Signature", "ts="+ts+";h1="+sig)
+}
+
+func signShopify(s *Signer, h http.Header, body []byte, now time.Time) {
+	h.Set("X-Shopify-Hmac-Sha256", base64.StdEncoding.EncodeToString(computeHMAC([]byte(s.secret), string(body))))
+}
+
+// signSvix keeps the message ID and header family of the original request
+func signSvix(s *Signer, h http.Header, body []byte, now time.Time) {
+	prefix := "Svix-"
+	if h.Get("Svix-Signature") == "" && h.Get("Webhook-Signature") != "" {
+		prefix = "Webhook-"
+	}
+	id := h.Get(prefix + "Id")
+	if id == "" {
+		id = fmt.Sprintf("msg_%d", now.UnixNano())
+		h.Set(prefix+"Id", id)
+	}
+	ts := strconv.FormatInt(now.Unix(), 10)
+
+	// Validated when the signer was created
+	secret, _ := svixSecret(s.secret)
+	sig := base64.StdEncoding.EncodeToString(computeHMAC(secret, id, ".", ts, ".", string(body)))
+	h.Set(prefix+"Timestamp", ts)
+	h.Set(prefix+"Signature", "v1,"+sig)
+}
+
+func signSlack(s *Signer, h http.Header, body []byte, now time.Time) {
+	ts := strconv.FormatInt(now.Unix(), 10)
+	h.Set("X-Slack-Request-Timestamp", ts)
+	h.Set("X-Slack-Signature", "v0="+hex.EncodeToString(computeHMAC([]byte(s.secret), "v0:", ts, ":", string(body))))
+}
+
+// signHMAC writes a hex encoded HMAC of the body, keeping a "sha256=" prefix if the
+// original signature had one
+func signHMAC(s *Signer, h http.Header, body []byte, now time.Time) {
+	sig := hex.EncodeToString(computeHMAC([]byte(s.secret), string(body)))
+	if strings.HasPrefix(h.Get(s.Header), "sha256=") {
+		sig = "sha256=" + sig
+	}
+	h.Set(s.Header, sig)
+}
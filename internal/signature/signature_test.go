@@ -0,0 +1,270 @@
+package signature
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// Example body for the vectors that are not taken from provider documentation
+const testBody = `{"id":"evt_test_webhook","object":"event"}`
+
+// Slack's example request from "Verifying requests from Slack"
+const slackBody = "token=xyzz0WbapA4vBCDEFasx0q6G&team_id=T1DC2JH3J&team_domain=testteamnow&channel_id=G8PSS9T3V&channel_name=foobar&user_id=U2CERLKJA&user_name=roadrunner&command=%2Fwebhook-collect&text=&response_url=https%3A%2F%2Fhooks.slack.com%2Fcommands%2FT1DC2JH3J%2F397700885554%2F96rGlfmibIGlgcZRskXaIFfN&trigger_id=398738663015.47445629121.803a0bc887a14d10d2c447fce8b6703c"
+
+var vectors = []struct {
+	name     string
+	provider string
+	secret   string
+	header   string
+	headers  map[string][]string
+	body     string
+}{
+	{
+		// GitHub's example from "Validating webhook deliveries"
+		name:     "github",
+		provider: "github",
+		secret:   "It's a Secret to Everybody",
+		headers:  map[string][]string{"X-Hub-Signature-256": {"sha256=757107ea0eb2509fc211221cce984b8a37570b6d7586c22c46f4379c8b043e17"}},
+		body:     "Hello, World!",
+	},
+	{
+		// Svix's example from "Verifying webhooks manually"
+		name:     "svix",
+		provider: "svix",
+		secret:   "whsec_MfKQ9r8GKYqrTwjUPD8ILPZIo2LaLaSw",
+		headers: map[string][]string{
+			"svix-id":        {"msg_p5jXN8AQM9LWM0D4loKWxJek"},
+			"svix-timestamp": {"1614265330"},
+			"svix-signature": {"v1,g0hM9SsE+OTPJTGt/tmIKtSyZlE3uFJELVlNIOLJ1OE="},
+		},
+		body: `{"test": 2432232314}`,
+	},
+	{
+		name:     "standard webhooks",
+		provider: "svix",
+		secret:   "whsec_MfKQ9r8GKYqrTwjUPD8ILPZIo2LaLaSw",
+		headers: map[string][]string{
+			"webhook-id":        {"msg_p5jXN8AQM9LWM0D4loKWxJek"},
+			"webhook-timestamp": {"1614265330"},
+			"webhook-signature": {"v1,bm90IHRoaXMgb25l v1,g0hM9SsE+OTPJTGt/tmIKtSyZlE3uFJELVlNIOLJ1OE="},
+		},
+		body: `{"test": 2432232314}`,
+	},
+	{
+		name:     "slack",
+		provider: "slack",
+		secret:   "8f742231b10e8888abcd99yyyzzz85a5",
+		headers: map[string][]string{
+			"X-Slack-Request-Timestamp": {"1531420618"},
+			"X-Slack-Signature":         {"v0=a2114d57b48eac39b9ad189dd8316235a7b4a8d21a10bd27519666489c69b503"},
+		},
+		body: slackBody,
+	},
+	{
+		name:     "stripe",
+		provider: "stripe",
+		secret:   "whsec_test_secret",
+		headers:  map[string][]string{"Stripe-Signature": {"t=1492774577,v1=88a022085c6bdb887b02cb26ff76dd681234d9675c0f22844059f55552a8883a,v0=6ffbb59b2300aae63f272406069a9788598b792a944a07aba816edb039989a39"}},
+		body:     testBody,
+	},
+	{
+		name:     "paddle",
+		provider: "paddle",
+		secret:   "pdl_ntfset_secret",
+		headers:  map[string][]string{"Paddle-Signature": {"ts=1671552777;h1=e9fafdd6750580c43bd759e751cfd0d1897a8001fb37c03a457760cfcc714ba3"}},
+		body:     testBody,
+	},
+	{
+		name:     "shopify",
+		provider: "shopify",
+		secret:   "shpss_secret",
+		headers:  map[string][]string{"X-Shopify-Hmac-Sha256": {"lLBH6Rw11NFjQ14xv5mmTBbELulDPwWuEj9UnBkI8Js="}},
+		body:     testBody,
+	},
+	{
+		name:     "hmac-sha256 hex",
+		provider: "hmac-sha256",
+		secret:   "secret",
+		headers:  map[string][]string{"X-Signature": {"sha256=ab3fe32a0dfaf30eac21f62eda422432b624a3a83a6938fac49a9485b5dcfb33"}},
+		body:     testBody,
+	},
+	{
+		name:     "hmac-sha256 base64 in custom header",
+		provider: "hmac-sha256",
+		secret:   "secret",
+		header:   "X-Webhook-Signature",
+		headers:  map[string][]string{"X-Webhook-Signature": {"qz/jKg368w6sIfYu2kIkMrYko6g6aTj6xJqUhbXc+zM="}},
+		body:     testBody,
+	},
+}
+
+func TestVerify(t *testing.T) {
+	for _, tt := range vectors {
+		t.Run(tt.name, func(t *testing.T) {
+			v, err := NewVerifier(tt.provider, tt.secret, tt.header)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := v.Verify(tt.headers, []byte(tt.body)); err != nil {
+				t.Errorf("Verify() error = %v", err)
+			}
+		})
+	}
+}
+
+func TestVerifyTamperedBody(t *testing.T) {
+	for _, tt := range vectors {
+		t.Run(tt.name, func(t *testing.T) {
+			v, err := NewVerifier(tt.provider, tt.secret, tt.header)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := v.Verify(tt.headers, []byte(tt.body+" ")); !errors.Is(err, ErrInvalidSignature) {
+				t.Errorf("Verify() error = %v, want ErrInvalidSignature", err)
+			}
+		})
+	}
+}
+
+func TestVerifyWrongSecret(t *testing.T) {
+	for _, tt := range vectors {
+		t.Run(tt.name, func(t *testing.T) {
+			secret := "other"
+			if tt.provider == "svix" {
+				secret = "whsec_b3RoZXI="
+			}
+			v, err := NewVerifier(tt.provider, secret, tt.header)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := v.Verify(tt.headers, []byte(tt.body)); !errors.Is(err, ErrInvalidSignature) {
+				t.Errorf("Verify() error = %v, want ErrInvalidSignature", err)
+			}
+		})
+	}
+}
+
+func TestVerifyMissingHeader(t *testing.T) {
+	for _, provider := range Providers() {
+		t.Run(provider, func(t *testing.T) {
+			v, err := NewVerifier(provider, "whsec_c2VjcmV0", "")
+			if err != nil {
+				t.Fatal(err)
+			}
+			headers := map[string][]string{"Content-Type": {"application/json"}}
+			if err := v.Verify(headers, []byte(testBody)); !errors.Is(err, ErrMissingSignature) {
+				t.Errorf("Verify() error = %v, want ErrMissingSignature", err)
+			}
+		})
+	}
+}
+
+func TestVerifyMalformedHeader(t *testing.T) {
+	tests := []struct {
+		provider string
+		headers  map[string][]string
+	}{
+		{"stripe", map[string][]string{"Stripe-Signature": {"v1=abc"}}},
+		{"github", map[string][]string{"X-Hub-Signature-256": {"abc"}}},
+		{"paddle", map[string][]string{"Paddle-Signature": {"h1=abc"}}},
+		{"svix", map[string][]string{"Svix-Signature": {"v1,abc"}}},
+		{"slack", map[string][]string{"X-Slack-Signature": {"v0=abc"}}},
+		{"slack", map[string][]string{"X-Slack-Signature": {"abc"}, "X-Slack-Request-Timestamp": {"1"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.provider, func(t *testing.T) {
+			v, err := NewVerifier(tt.provider, "whsec_c2VjcmV0", "")
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = v.Verify(tt.headers, []byte(testBody))
+			if err == nil || errors.Is(err, ErrInvalidSignature) || errors.Is(err, ErrMissingSignature) {
+				t.Errorf("Verify() error = %v, want a malformed header error", err)
+			}
+		})
+	}
+}
+
+func TestSignVerifyRoundTrip(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	for _, provider := range Providers() {
+		t.Run(provider, func(t *testing.T) {
+			secret := "whsec_c2VjcmV0" // valid for every scheme, including svix
+			signer, err := NewSigner(provider, secret, "")
+			if err != nil {
+				t.Fatal(err)
+			}
+			verifier, err := NewVerifier(provider, secret, "")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			h := make(http.Header)
+			signer.Sign(h, []byte(testBody), now)
+			if err := verifier.Verify(h, []byte(testBody)); err != nil {
+				t.Errorf("Verify() of a signed request error = %v", err)
+			}
+			if err := verifier.Verify(h, []byte(testBody+" ")); !errors.Is(err, ErrInvalidSignature) {
+				t.Errorf("Verify() of a tampered request error = %v, want ErrInvalidSignature", err)
+			}
+		})
+	}
+}
+
+func TestSignReplacesVerifiedSignature(t *testing.T) {
+	// Re-signing a request that carried a valid signature for another secret
+	for _, tt := range vectors {
+		t.Run(tt.name, func(t *testing.T) {
+			h := make(http.Header)
+			for key, values := range tt.headers {
+				for _, value := range values {
+					h.Add(key, value)
+				}
+			}
+			secret := "local"
+			if tt.provider == "svix" {
+				secret = "whsec_bG9jYWw="
+			}
+			signer, err := NewSigner(tt.provider, secret, tt.header)
+			if err != nil {
+				t.Fatal(err)
+			}
+			signer.Sign(h, []byte(tt.body), time.Now())
+
+			verifier, err := NewVerifier(tt.provider, secret, tt.header)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := verifier.Verify(h, []byte(tt.body)); err != nil {
+				t.Errorf("Verify() with the local secret error = %v", err)
+			}
+			original, err := NewVerifier(tt.provider, tt.secret, tt.header)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := original.Verify(h, []byte(tt.body)); !errors.Is(err, ErrInvalidSignature) {
+				t.Errorf("Verify() with the original secret error = %v, want ErrInvalidSignature", err)
+			}
+		})
+	}
+}
+
+func TestNewVerifierErrors(t *testing.T) {
+	tests := []struct {
+		provider, secret string
+	}{
+		{"unknown", "secret"},
+		{"stripe", ""},
+		{"svix", "whsec_not base64!"},
+	}
+	for _, tt := range tests {
+		if _, err := NewVerifier(tt.provider, tt.secret, ""); err == nil {
+			t.Errorf("NewVerifier(%q, %q) succeeded, want an error", tt.provider, tt.secret)
+		}
+		if _, err := NewSigner(tt.provider, tt.secret, ""); err == nil {
+			t.Errorf("NewSigner(%q, %q) succeeded, want an error", tt.provider, tt.secret)
+		}
+	}
+}